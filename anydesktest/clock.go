@@ -0,0 +1,53 @@
+package anydesktest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is an anydesk.Clock that only moves when told to, for deterministic tests
+// of signing, clock skew, caching and time windows. Sleep advances the clock immediately.
+//
+//   clock := anydesktest.NewFakeClock(time.Unix(1590504000, 0))
+//   api.Clock = clock
+//   server.Clock = clock
+//
+//   clock.Advance(10 * time.Minute)
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a fake clock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Sleep advances the clock by the given duration without blocking.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to the given time.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
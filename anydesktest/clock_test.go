@@ -0,0 +1,184 @@
+package anydesktest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(1590504000, 0)
+	c := NewFakeClock(start)
+
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+
+	c.Sleep(time.Hour)
+	assert.Equal(t, start.Add(time.Hour+time.Minute), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
+
+func TestFakeClock_Signing(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	serverClock := NewFakeClock(time.Unix(1590504000, 0))
+	clientClock := NewFakeClock(time.Unix(1590504000, 0))
+	s.Clock = serverClock
+
+	api := s.API()
+	api.Clock = clientClock
+
+	req := anydesk.NewAuthenticationRequest()
+	_, err := req.Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1590504000), req.Timestamp)
+	assert.Equal(t, time.Unix(1590504000, 0), req.Meta().FetchedAt)
+
+	// client clocks running ahead are rejected once beyond the tolerated skew
+	clientClock.Advance(DefaultMaxClockSkew - time.Second)
+
+	_, err = anydesk.NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+
+	clientClock.Advance(2 * time.Second)
+
+	_, err = anydesk.NewAuthenticationRequest().Do(api)
+	assert.IsType(t, &anydesk.APIBadCredentialsError{}, err)
+}
+
+func TestFakeClock_Cache(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	clock := NewFakeClock(time.Unix(1590504000, 0))
+	s.Clock = clock
+
+	calls := 0
+	s.Inject(Rule{Path: "/sysinfo", Fault: countCalls(&calls)})
+
+	api := s.API()
+	api.Clock = clock
+	api.Cache = anydesk.NewResponseCache(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := anydesk.NewSysinfoRequest().Do(api)
+		assert.NoError(t, err)
+
+		clock.Advance(59 * time.Second / 3)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	clock.Advance(time.Minute)
+
+	resp, err := anydesk.NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, anydesk.CacheMiss, resp.Meta.CacheStatus)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFakeClock_RateLimiter(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1590504000, 0))
+
+	limiter := anydesk.NewRateLimiter(1, 1)
+	limiter.Clock = clock
+
+	limiter.Wait()
+	limiter.Wait()
+	limiter.Wait()
+
+	// waiting advanced the fake clock instead of blocking
+	assert.Equal(t, time.Unix(1590504002, 0), clock.Now())
+}
+
+func TestFakeClock_APIRateLimiter(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	clock := NewFakeClock(time.Unix(1590504000, 0))
+	s.Clock = clock
+
+	// the limiter uses the clock of the API
+	api := s.API()
+	api.Clock = clock
+	api.RateLimiter = anydesk.NewRateLimiter(1, 1)
+
+	for i := 0; i < 3; i++ {
+		_, err := anydesk.NewAuthenticationRequest().Do(api)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, time.Unix(1590504002, 0), clock.Now())
+}
+
+func TestFakeClock_CircuitBreaker(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	clock := NewFakeClock(time.Unix(1590504000, 0))
+	s.Clock = clock
+	s.Inject(Rule{Path: "/auth", Count: 2, Fault: ServerError(http.StatusServiceUnavailable)})
+
+	// the breaker uses the clock of the API
+	api := s.API()
+	api.Clock = clock
+	api.CircuitBreaker = anydesk.NewCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := anydesk.NewAuthenticationRequest().Do(api)
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, anydesk.CircuitOpen, api.CircuitBreaker.State())
+
+	clock.Advance(59 * time.Second)
+
+	_, err := anydesk.NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, anydesk.ErrCircuitOpen))
+	assert.Equal(t, anydesk.CircuitOpen, api.CircuitBreaker.State())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, anydesk.CircuitHalfOpen, api.CircuitBreaker.State())
+
+	_, err = anydesk.NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, anydesk.CircuitClosed, api.CircuitBreaker.State())
+}
+
+func TestFakeClock_LicenseExpiresIn(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	clock := NewFakeClock(time.Unix(1590504000, 0))
+	s.Clock = clock
+	s.Sysinfo.License.ExpiresTimestamp = 1590504000 + 3600
+
+	api := s.API()
+	api.Clock = clock
+
+	resp, err := anydesk.NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, resp.License.ExpiresIn())
+
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, -time.Hour, resp.License.ExpiresIn())
+}
+
+// countCalls is a fault that only counts the requests passing through.
+func countCalls(calls *int) Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			*calls++
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
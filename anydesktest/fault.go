@@ -0,0 +1,190 @@
+package anydesktest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// Fault wraps the regular request handling of the server to simulate a failure.
+// A fault can either replace the response completely or alter the request or response.
+type Fault func(next http.Handler) http.Handler
+
+// Rule describes when a fault should be injected into the request handling.
+type Rule struct {
+	// Limit the rule to the given http method, empty matches all methods.
+	Method string
+
+	// Limit the rule to the given resource path, i.e. "/sysinfo", empty matches all resources.
+	Path string
+
+	// Number of the first matching request the fault is applied to, starting at 1.
+	// Zero is treated like 1.
+	From int
+
+	// Number of matching requests the fault is applied to, starting with From.
+	// Zero applies the fault to all following requests.
+	Count int
+
+	// The fault to apply.
+	Fault Fault
+
+	seen int
+}
+
+// Inject adds a rule to the server. Rules are applied in the order they were added.
+// Requests are counted per rule, so scenarios stay deterministic for any given request sequence.
+func (s *Server) Inject(rule Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := rule
+	r.seen = 0
+	s.rules = append(s.rules, &r)
+}
+
+// ResetFaults removes all previously injected rules.
+func (s *Server) ResetFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = nil
+}
+
+func (s *Server) matchFaults(req *http.Request) []Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var faults []Fault
+
+	for _, r := range s.rules {
+		if r.Method != "" && r.Method != req.Method {
+			continue
+		}
+
+		if r.Path != "" && r.Path != req.URL.Path {
+			continue
+		}
+
+		r.seen++
+
+		from := r.From
+		if from < 1 {
+			from = 1
+		}
+
+		if r.seen < from {
+			continue
+		}
+
+		if r.Count > 0 && r.seen >= from+r.Count {
+			continue
+		}
+
+		faults = append(faults, r.Fault)
+	}
+
+	return faults
+}
+
+// Latency delays the request handling by the given duration.
+func Latency(d time.Duration) Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			select {
+			case <-time.After(d):
+			case <-req.Context().Done():
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// ServerError responds with the given http status code, i.e. http.StatusBadGateway.
+func ServerError(statusCode int) Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			writeJSON(rw, statusCode, errorResponse("server_error", http.StatusText(statusCode)))
+		})
+	}
+}
+
+// TooManyRequests responds with http.StatusTooManyRequests and the given "Retry-After" header in seconds.
+func TooManyRequests(retryAfter time.Duration) Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Retry-After", strconv.FormatInt(int64(retryAfter/time.Second), 10))
+			writeJSON(rw, http.StatusTooManyRequests, errorResponse("rate_limited", "too many requests"))
+		})
+	}
+}
+
+// TruncatedBody processes the request regularly, but only sends the first half of the response body.
+func TruncatedBody() Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rec := httptest.NewRecorder()
+			next.ServeHTTP(rec, req)
+
+			body := rec.Body.Bytes()
+			body = body[:len(body)/2]
+
+			for k, v := range rec.Header() {
+				rw.Header()[k] = v
+			}
+
+			rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			rw.WriteHeader(rec.Code)
+			_, _ = rw.Write(body)
+		})
+	}
+}
+
+// ConnectionReset closes the underlying connection without sending any response.
+func ConnectionReset() Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			hj, ok := rw.(http.Hijacker)
+			if !ok {
+				panic(http.ErrAbortHandler)
+			}
+
+			conn, _, err := hj.Hijack()
+			if err != nil {
+				panic(http.ErrAbortHandler)
+			}
+
+			if tcp, ok := conn.(interface{ SetLinger(int) error }); ok {
+				_ = tcp.SetLinger(0)
+			}
+
+			_ = conn.Close()
+		})
+	}
+}
+
+// ClockSkew shifts the server clock by the given offset while verifying the request timestamp.
+// An offset beyond Server.MaxClockSkew leads to an "invalid_token" rejection, like a client
+// with a badly synchronized clock would receive.
+func ClockSkew(offset time.Duration) Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), clockSkewKey, offset)
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
+
+// ExpiredLicense reports the license as expired on "/sysinfo" responses.
+func ExpiredLicense() Fault {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), expiredLicenseKey, true)
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
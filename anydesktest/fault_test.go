@@ -0,0 +1,178 @@
+package anydesktest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_InjectCounting(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{
+		Path:  "/sysinfo",
+		From:  2,
+		Count: 2,
+		Fault: ServerError(http.StatusServiceUnavailable),
+	})
+
+	api := s.API()
+
+	var failed []bool
+	for i := 0; i < 5; i++ {
+		_, err := anydesk.NewSysinfoRequest().Do(api)
+		failed = append(failed, err != nil)
+	}
+
+	// Other resources are not counted or affected
+	_, err := anydesk.NewAuthenticationRequest().Do(api)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal([]bool{false, true, true, false, false}, failed)
+}
+
+func TestServer_InjectMethod(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Method: http.MethodPatch, Fault: ServerError(http.StatusBadGateway)})
+
+	api := s.API()
+
+	_, err := anydesk.NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+
+	_, err = api.Do(anydesk.NewSessionCommentChangeRequest("S1", "TEST"))
+	assert.EqualError(t, err, "502 Bad Gateway")
+}
+
+func TestServer_ResetFaults(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Fault: ServerError(http.StatusInternalServerError)})
+	s.ResetFaults()
+
+	_, err := anydesk.NewAuthenticationRequest().Do(s.API())
+	assert.NoError(t, err)
+}
+
+func TestLatency(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Path: "/auth", Fault: Latency(100 * time.Millisecond)})
+
+	start := time.Now()
+	_, err := anydesk.NewAuthenticationRequest().Do(s.API())
+
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestTooManyRequests(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Fault: TooManyRequests(30 * time.Second)})
+
+	resp, err := s.Client().Do(signedRequest(t, s.API(), "GET", "/auth"))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+}
+
+func TestTruncatedBody(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Path: "/sysinfo", Fault: TruncatedBody()})
+
+	_, err := anydesk.NewSysinfoRequest().Do(s.API())
+	assert.Error(t, err)
+	assert.IsType(t, &json.SyntaxError{}, err)
+}
+
+func TestConnectionReset(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Count: 1, Fault: ConnectionReset()})
+
+	api := s.API()
+
+	_, err := anydesk.NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+
+	_, err = anydesk.NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+}
+
+func TestClockSkew(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{From: 2, Fault: ClockSkew(time.Hour)})
+
+	api := s.API()
+
+	resp, err := s.Client().Do(signedRequest(t, api, "GET", "/auth"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = s.Client().Do(signedRequest(t, api, "GET", "/auth"))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	r := &anydesk.AuthenticationResponse{}
+	assert.NoError(t, json.Unmarshal(body, r))
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "invalid_token", r.Code)
+	assert.Equal(t, "/auth", r.Resource)
+}
+
+func TestClockSkew_WithinTolerance(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Fault: ClockSkew(time.Minute)})
+
+	_, err := anydesk.NewAuthenticationRequest().Do(s.API())
+	assert.NoError(t, err)
+}
+
+func TestExpiredLicense(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Inject(Rule{Path: "/sysinfo", From: 2, Count: 1, Fault: ExpiredLicense()})
+
+	api := s.API()
+
+	r1, err := anydesk.NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+	r2, err := anydesk.NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+
+	a := assert.New(t)
+	a.False(r1.License.HasExpired)
+	a.True(r2.License.HasExpired)
+	a.True(r2.License.ExpiresTimestamp < time.Now().Unix())
+
+	// The server data itself is left untouched
+	a.False(s.Sysinfo.License.HasExpired)
+}
@@ -0,0 +1,248 @@
+package anydesktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// Fixtures contains the data loaded from a directory of JSON fixture files.
+//
+// The files use the same shape as the AnyDesk API responses, the shape is detected by content:
+//   - list responses of "/clients" or "/sessions", i.e. "_tests/client_list_all.json"
+//   - client details of "/clients/{cid}", including their last sessions, i.e. "_tests/client_detail.json"
+//   - a single session node
+//   - a "/sysinfo" response
+// Any other JSON file is ignored.
+type Fixtures struct {
+	// Directory the fixtures were loaded from.
+	Dir string
+
+	// Sysinfo as found in the fixtures, nil if none was given.
+	Sysinfo *anydesk.SysinfoResponse
+
+	// Clients found in the fixtures, ordered by client ID.
+	Clients []anydesk.ClientNode
+
+	// Sessions found in the fixtures, ordered by start time.
+	Sessions []anydesk.SessionNode
+
+	// raw decoded content by file path, used to write changes back
+	files map[string]interface{}
+}
+
+// LoadFixtures reads all "*.json" files of the given directory.
+func LoadFixtures(dir string) (f *Fixtures, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	f = &Fixtures{
+		Dir:   dir,
+		files: make(map[string]interface{}),
+	}
+
+	clients := make(map[int64]anydesk.ClientNode)
+	sessions := make(map[string]anydesk.SessionNode)
+
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		f.files[path] = raw
+
+		if err := f.collect(data, obj, clients, sessions); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	for _, c := range clients {
+		c.LastSessions = nil
+		f.Clients = append(f.Clients, c)
+	}
+
+	for _, n := range sessions {
+		f.Sessions = append(f.Sessions, n)
+	}
+
+	sort.Slice(f.Clients, func(i, j int) bool {
+		return f.Clients[i].ClientID < f.Clients[j].ClientID
+	})
+
+	sort.Slice(f.Sessions, func(i, j int) bool {
+		if f.Sessions[i].StartTimestamp == f.Sessions[j].StartTimestamp {
+			return f.Sessions[i].SessionID < f.Sessions[j].SessionID
+		}
+
+		return f.Sessions[i].StartTimestamp < f.Sessions[j].StartTimestamp
+	})
+
+	return
+}
+
+// collect detects the shape of a single fixture file and adds its content.
+func (f *Fixtures) collect(
+	data []byte,
+	obj map[string]interface{},
+	clients map[int64]anydesk.ClientNode,
+	sessions map[string]anydesk.SessionNode,
+) error {
+	switch {
+	case obj["list"] != nil:
+		list := struct {
+			List []json.RawMessage `json:"list"`
+		}{}
+
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+
+		for _, item := range list.List {
+			if err := f.collectNode(item, clients, sessions); err != nil {
+				return err
+			}
+		}
+	case obj["api-ver"] != nil:
+		f.Sysinfo = &anydesk.SysinfoResponse{}
+		return json.Unmarshal(data, f.Sysinfo)
+	default:
+		return f.collectNode(data, clients, sessions)
+	}
+
+	return nil
+}
+
+// collectNode adds a single client or session node, detected by its ID property.
+func (f *Fixtures) collectNode(
+	data []byte,
+	clients map[int64]anydesk.ClientNode,
+	sessions map[string]anydesk.SessionNode,
+) error {
+	ids := struct {
+		SessionID *string `json:"sid"`
+		ClientID  *int64  `json:"cid"`
+	}{}
+
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return err
+	}
+
+	switch {
+	case ids.SessionID != nil:
+		n := anydesk.SessionNode{}
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+
+		sessions[n.SessionID] = n
+	case ids.ClientID != nil:
+		c := anydesk.ClientNode{}
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+
+		for _, n := range c.LastSessions {
+			sessions[n.SessionID] = n
+		}
+
+		// Details contain more information than list entries, so they take precedence
+		if prev, ok := clients[c.ClientID]; !ok || len(c.LastSessions) > 0 || prev.ClientVersion == "" {
+			clients[c.ClientID] = c
+		}
+	}
+
+	return nil
+}
+
+// Apply replaces the data of the given server with the fixtures.
+func (f *Fixtures) Apply(s *Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Sysinfo != nil {
+		s.Sysinfo = f.Sysinfo
+	}
+
+	s.Clients = f.Clients
+	s.Sessions = f.Sessions
+}
+
+// Save writes the session comments of the given server back into the fixture files they were loaded from.
+// Only files with changes are written. The server data must not change while saving, so either
+// call it from Server.OnChange or while no requests are served.
+func (f *Fixtures) Save(s *Server) error {
+	comments := make(map[string]string, len(s.Sessions))
+	for _, n := range s.Sessions {
+		comments[n.SessionID] = n.Comment
+	}
+
+	for path, raw := range f.files {
+		if !updateComments(raw, comments) {
+			continue
+		}
+
+		data, err := json.MarshalIndent(raw, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateComments walks the raw JSON and updates the comment of all session nodes.
+// Returns true if anything was changed.
+func updateComments(raw interface{}, comments map[string]string) (changed bool) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if sid, ok := v["sid"].(string); ok {
+			if comment, ok := comments[sid]; ok {
+				prev, _ := v["comment"].(string)
+
+				if prev != comment {
+					v["comment"] = comment
+					if comment == "" {
+						v["comment"] = nil
+					}
+
+					changed = true
+				}
+			}
+		}
+
+		for _, child := range v {
+			if updateComments(child, comments) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if updateComments(child, comments) {
+				changed = true
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,95 @@
+package anydesktest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	f, err := LoadFixtures("../_tests")
+
+	a := assert.New(t)
+	a.NoError(err)
+
+	// 8 from the list, plus 1 unknown client referenced by the client detail
+	a.Len(f.Clients, 9)
+	// 2 from the client detail, 2 from the session list
+	a.Len(f.Sessions, 4)
+	a.Equal("SESSIONB", f.Sessions[0].SessionID)
+	a.Equal("SESSION1", f.Sessions[1].SessionID)
+	a.Equal("SESSIONA", f.Sessions[2].SessionID)
+	a.Equal("SESSION2", f.Sessions[3].SessionID)
+
+	a.NotNil(f.Sysinfo)
+	a.Equal("TEST_LICENSE_ID", f.Sysinfo.License.ID)
+
+	for _, c := range f.Clients {
+		a.Nil(c.LastSessions)
+	}
+}
+
+func TestFixtures_Apply(t *testing.T) {
+	f, err := LoadFixtures("../_tests")
+	assert.NoError(t, err)
+
+	s := NewServer("TEST_LICENSE", "TEST_PASSWORD")
+	defer s.Close()
+
+	f.Apply(s)
+
+	resp, err := anydesk.NewClientDetailRequest(100000000).Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("xyz", resp.Alias)
+	a.Len(resp.LastSessions, 4)
+	a.Equal("SESSION2", resp.LastSessions[0].SessionID)
+}
+
+func TestFixtures_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "anydesktest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"client_detail.json", "client_list_all.json"} {
+		data, err := ioutil.ReadFile(filepath.Join("../_tests", name))
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+
+	f, err := LoadFixtures(dir)
+	assert.NoError(t, err)
+
+	s := NewUnstartedServer("TEST_LICENSE", "TEST_PASSWORD")
+	s.OnChange = func() {
+		assert.NoError(t, f.Save(s))
+	}
+
+	s.Start()
+	defer s.Close()
+
+	f.Apply(s)
+
+	_, err = s.API().Do(anydesk.NewSessionCommentChangeRequest("SESSIONA", "CHANGED"))
+	assert.NoError(t, err)
+
+	// Reload from disk
+	f, err = LoadFixtures(dir)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("SESSIONA", f.Sessions[1].SessionID)
+	a.Equal("CHANGED", f.Sessions[1].Comment)
+
+	list, err := ioutil.ReadFile(filepath.Join(dir, "client_list_all.json"))
+	a.NoError(err)
+
+	orig, err := ioutil.ReadFile("../_tests/client_list_all.json")
+	a.NoError(err)
+	a.Equal(orig, list, "unchanged files must not be written")
+}
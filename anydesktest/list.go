@@ -0,0 +1,159 @@
+package anydesktest
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// listQuery contains the pagination settings of a list request.
+type listQuery struct {
+	offset int64
+	limit  int64
+	sort   string
+	order  anydesk.SortOrder
+}
+
+func parseListQuery(q url.Values) listQuery {
+	lq := listQuery{
+		offset: 0,
+		limit:  anydesk.Infinite,
+		sort:   q.Get("sort"),
+		order:  anydesk.SortOrder(strings.ToLower(q.Get("order"))),
+	}
+
+	if v, err := strconv.ParseInt(q.Get("offset"), 10, 64); err == nil && v > 0 {
+		lq.offset = v
+	}
+
+	if v, err := strconv.ParseInt(q.Get("limit"), 10, 64); err == nil && v >= 0 {
+		lq.limit = v
+	}
+
+	return lq
+}
+
+// window returns the start and end index of the requested page for a list of the given size.
+func (lq listQuery) window(size int) (start int, end int) {
+	start = int(lq.offset)
+	if start > size {
+		start = size
+	}
+
+	end = size
+	if lq.limit != anydesk.Infinite && start+int(lq.limit) < end {
+		end = start + int(lq.limit)
+	}
+
+	return
+}
+
+// paginated composes the list response for the given page of items.
+func (lq listQuery) paginated(count int, start int, end int, list interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"count":    count,
+		"selected": end - start,
+		"offset":   lq.offset,
+		"limit":    lq.limit,
+		"list":     list,
+	}
+}
+
+// clientSorters contains the sortable properties of clients.
+var clientSorters = map[string]func(a, b *anydesk.ClientNode) bool{
+	anydesk.ClientSortID:         func(a, b *anydesk.ClientNode) bool { return a.ClientID < b.ClientID },
+	anydesk.ClientSortAlias:      func(a, b *anydesk.ClientNode) bool { return a.Alias < b.Alias },
+	anydesk.ClientSortVersion:    func(a, b *anydesk.ClientNode) bool { return a.ClientVersion < b.ClientVersion },
+	anydesk.ClientSortOnline:     func(a, b *anydesk.ClientNode) bool { return !a.Online && b.Online },
+	anydesk.ClientSortOnlineTime: func(a, b *anydesk.ClientNode) bool { return a.OnlineSinceSeconds < b.OnlineSinceSeconds },
+	anydesk.ClientSortComment:    func(a, b *anydesk.ClientNode) bool { return a.Comment < b.Comment },
+}
+
+// sessionSorters contains the sortable properties of sessions.
+var sessionSorters = map[string]func(a, b *anydesk.SessionNode) bool{
+	anydesk.SessionSortID:        func(a, b *anydesk.SessionNode) bool { return a.SessionID < b.SessionID },
+	anydesk.SessionSortStartTime: func(a, b *anydesk.SessionNode) bool { return a.StartTimestamp < b.StartTimestamp },
+	anydesk.SessionSortEndTime:   func(a, b *anydesk.SessionNode) bool { return a.EndTimestamp < b.EndTimestamp },
+	anydesk.SessionSortDuration:  func(a, b *anydesk.SessionNode) bool { return a.DurationInSeconds < b.DurationInSeconds },
+	anydesk.SessionSortComment:   func(a, b *anydesk.SessionNode) bool { return a.Comment < b.Comment },
+}
+
+// filterClients returns the clients matching the given query, sorted as requested.
+func filterClients(clients []anydesk.ClientNode, q url.Values, lq listQuery) []anydesk.ClientNode {
+	list := make([]anydesk.ClientNode, 0, len(clients))
+
+	for _, c := range clients {
+		if v := q.Get("online"); v != "" && strconv.FormatBool(c.Online) != v {
+			continue
+		}
+
+		list = append(list, c)
+	}
+
+	if less, ok := clientSorters[lq.sort]; ok {
+		sort.SliceStable(list, func(i, j int) bool {
+			if lq.order == anydesk.OrderDesc {
+				return less(&list[j], &list[i])
+			}
+
+			return less(&list[i], &list[j])
+		})
+	}
+
+	return list
+}
+
+// filterSessions returns the sessions matching the given query, sorted as requested.
+func filterSessions(sessions []anydesk.SessionNode, q url.Values, lq listQuery) []anydesk.SessionNode {
+	list := make([]anydesk.SessionNode, 0, len(sessions))
+
+	cid, _ := strconv.ParseInt(q.Get("cid"), 10, 64)
+	from, _ := strconv.ParseInt(q.Get("from"), 10, 64)
+	to, _ := strconv.ParseInt(q.Get("to"), 10, 64)
+
+	for _, n := range sessions {
+		if cid > 0 && !matchDirection(&n, cid, anydesk.SessionDirection(q.Get("direction"))) {
+			continue
+		}
+
+		if from > 0 && n.StartTimestamp < from {
+			continue
+		}
+
+		if to > 0 && n.StartTimestamp > to {
+			continue
+		}
+
+		list = append(list, n)
+	}
+
+	if less, ok := sessionSorters[lq.sort]; ok {
+		sort.SliceStable(list, func(i, j int) bool {
+			if lq.order == anydesk.OrderDesc {
+				return less(&list[j], &list[i])
+			}
+
+			return less(&list[i], &list[j])
+		})
+	}
+
+	return list
+}
+
+// matchDirection checks if the session involved the given client in the given direction.
+func matchDirection(n *anydesk.SessionNode, cid int64, direction anydesk.SessionDirection) bool {
+	in := n.Target != nil && n.Target.ClientID == cid
+	out := n.Source != nil && n.Source.ClientID == cid
+
+	switch direction {
+	case anydesk.DirectionIn:
+		return in
+	case anydesk.DirectionOut:
+		return out
+	default:
+		return in || out
+	}
+}
@@ -0,0 +1,106 @@
+package anydesktest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ClientListPagination(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Clients = append(s.Clients, anydesk.ClientNode{ClientID: 300, Alias: "gamma@ad", Online: true})
+
+	req := anydesk.NewClientListRequest(nil)
+	req.Offset = 1
+	req.Limit = 1
+	req.Sort = "cid"
+	req.Order = anydesk.OrderAsc
+
+	resp, err := req.Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal(int64(3), resp.Count)
+	a.Equal(int64(1), resp.Selected)
+	a.Equal(int64(1), resp.Offset)
+	a.Len(resp.List, 1)
+	a.Equal(int64(200), resp.List[0].ClientID)
+
+	next, hasMore := resp.HasMore(req)
+	a.True(hasMore)
+	a.Equal(int64(2), next.Offset)
+}
+
+func TestServer_ClientListOnline(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	req := anydesk.NewClientListRequest(&anydesk.ClientListSearch{Online: true})
+	resp, err := req.Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.True(resp.Online)
+	a.Len(resp.List, 1)
+	a.Equal(int64(100), resp.List[0].ClientID)
+}
+
+func TestServer_ClientListSortDesc(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	req := anydesk.NewClientListRequest(nil)
+	req.Sort = "alias"
+
+	resp, err := req.Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("beta@ad", resp.List[0].Alias)
+	a.Equal("alpha@ad", resp.List[1].Alias)
+}
+
+func TestServer_SessionListFilter(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	s.Sessions = append(s.Sessions, anydesk.SessionNode{
+		SessionID:      "S2",
+		Source:         &anydesk.ClientSlimNode{ClientID: 200},
+		Target:         &anydesk.ClientSlimNode{ClientID: 300},
+		StartTimestamp: 1600000000,
+	})
+
+	a := assert.New(t)
+
+	cases := []struct {
+		search *anydesk.SessionListSearch
+		count  int64
+	}{
+		{&anydesk.SessionListSearch{ClientID: 200}, 2},
+		{&anydesk.SessionListSearch{ClientID: 200, Direction: anydesk.DirectionIn}, 1},
+		{&anydesk.SessionListSearch{ClientID: 200, Direction: anydesk.DirectionOut}, 1},
+		{&anydesk.SessionListSearch{ClientID: 100, Direction: anydesk.DirectionIn}, 0},
+		{&anydesk.SessionListSearch{TimeFrom: time.Unix(1595000000, 0)}, 1},
+		{&anydesk.SessionListSearch{TimeTo: time.Unix(1595000000, 0)}, 1},
+	}
+
+	for _, c := range cases {
+		body, err := s.API().DoPaginated(anydesk.NewSessionListRequest(c.search))
+
+		if c.count == 0 {
+			a.IsType(&anydesk.APINoResultsError{}, err)
+			continue
+		}
+
+		r := &anydesk.PaginatedResult{}
+		a.NoError(err)
+		a.NoError(json.Unmarshal(body, r))
+		a.Equal(c.count, r.Count)
+	}
+}
@@ -0,0 +1,374 @@
+// Package anydesktest provides a scriptable AnyDesk API server for use in tests.
+//
+// The server verifies the request signature the same way the AnyDesk API does
+// and serves the data assigned to Server.Sysinfo, Server.Clients and Server.Sessions.
+// Faults like latency, error bursts or connection resets can be injected per
+// resource and per request count, see Server.Inject.
+//
+//   server := anydesktest.NewServer("license", "password")
+//   defer server.Close()
+//
+//   server.Inject(anydesktest.Rule{
+//       Path:  "/sysinfo",
+//       From:  2,
+//       Count: 3,
+//       Fault: anydesktest.ServerError(http.StatusServiceUnavailable),
+//   })
+//
+//   api := server.API()
+package anydesktest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// DefaultMaxClockSkew is the maximum accepted difference between the request timestamp
+// and the server time, before a request is rejected with "invalid_token".
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// Server is a stub of the AnyDesk API that can be used to run tests against.
+type Server struct {
+	*httptest.Server
+
+	// License ID the server accepts requests for.
+	LicenseID string
+
+	// API password used to verify the request signature.
+	APIPassword string
+
+	// Maximum accepted difference between request timestamp and server time.
+	MaxClockSkew time.Duration
+
+	// Accept requests with any credentials, the signature is not verified.
+	AcceptAnyCredentials bool
+
+	// Source of the server time, used to verify request timestamps. Defaults to anydesk.SystemClock.
+	Clock anydesk.Clock
+
+	// Called after a request changed the server data, i.e. a session comment.
+	// The server lock is held while called, so the data can be read safely.
+	OnChange func()
+
+	// Data returned by the "/sysinfo" resource.
+	Sysinfo *anydesk.SysinfoResponse
+
+	// Clients known to the server.
+	Clients []anydesk.ClientNode
+
+	// Sessions known to the server.
+	Sessions []anydesk.SessionNode
+
+	mu    sync.Mutex
+	rules []*Rule
+}
+
+// NewServer returns a started server that accepts requests signed with the given credentials.
+// The caller should call Close when finished, to shut it down.
+func NewServer(licenseID string, apiPassword string) *Server {
+	s := NewUnstartedServer(licenseID, apiPassword)
+	s.Start()
+
+	return s
+}
+
+// NewUnstartedServer returns a new server, but does not start it.
+// The caller should call Start when ready and Close when finished.
+func NewUnstartedServer(licenseID string, apiPassword string) *Server {
+	s := NewHandler(licenseID, apiPassword)
+	s.Server = httptest.NewUnstartedServer(s)
+
+	return s
+}
+
+// NewHandler returns a server without a test listener, to be served by the caller, i.e. with a http.Server.
+// Start, Close, URL and Client of the embedded httptest.Server are not available.
+func NewHandler(licenseID string, apiPassword string) *Server {
+	return &Server{
+		LicenseID:    licenseID,
+		APIPassword:  apiPassword,
+		MaxClockSkew: DefaultMaxClockSkew,
+		Sysinfo:      newSysinfo(licenseID, apiPassword),
+	}
+}
+
+// API returns an API configuration that works against the server with its credentials.
+func (s *Server) API() *anydesk.API {
+	api := anydesk.NewAPI(s.LicenseID, s.APIPassword)
+	api.APIEndpoint = s.URL
+	api.HTTPClient = s.Client()
+
+	return api
+}
+
+// ServeHTTP applies all matching fault rules, verifies the request signature
+// and dispatches the request to the resource handlers.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var h http.Handler = http.HandlerFunc(s.serveVerified)
+
+	// Wrap in reverse order, so the first injected rule is applied first
+	faults := s.matchFaults(req)
+	for i := len(faults) - 1; i >= 0; i-- {
+		h = faults[i](h)
+	}
+
+	h.ServeHTTP(rw, req)
+}
+
+func (s *Server) serveVerified(rw http.ResponseWriter, req *http.Request) {
+	if !s.AcceptAnyCredentials {
+		if _, err := s.verifier(req).Verify(req); err != nil {
+			anydesk.WriteVerificationError(rw, req, err)
+			return
+		}
+	}
+
+	content, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeJSON(rw, http.StatusBadRequest, errorResponse("bad_request", err.Error()))
+		return
+	}
+
+	s.route(rw, req, content)
+}
+
+// verifier returns the request verifier for the server credentials,
+// using the server clock as shifted by the ClockSkew fault.
+func (s *Server) verifier(req *http.Request) *anydesk.RequestVerifier {
+	v := anydesk.NewRequestVerifier(func(licenseID string) (string, error) {
+		if licenseID != s.LicenseID {
+			return "", errors.New("license not served")
+		}
+
+		return s.APIPassword, nil
+	})
+
+	v.MaxAge = s.MaxClockSkew
+	v.Now = func() time.Time {
+		return s.now().Add(clockSkew(req.Context()))
+	}
+
+	return v
+}
+
+// now returns the current server time.
+func (s *Server) now() time.Time {
+	if s.Clock == nil {
+		return anydesk.SystemClock.Now()
+	}
+
+	return s.Clock.Now()
+}
+
+func (s *Server) route(rw http.ResponseWriter, req *http.Request, content []byte) {
+	path := req.URL.Path
+
+	switch {
+	case req.Method == http.MethodGet && path == "/auth":
+		writeJSON(rw, http.StatusOK, map[string]interface{}{
+			"result":     "success",
+			"license-id": s.LicenseID,
+		})
+	case req.Method == http.MethodGet && path == "/sysinfo":
+		s.serveSysinfo(rw, req)
+	case req.Method == http.MethodGet && path == "/clients":
+		s.serveClientList(rw, req)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "/clients/"):
+		s.serveClientDetail(rw, strings.TrimPrefix(path, "/clients/"))
+	case req.Method == http.MethodGet && path == "/sessions":
+		s.serveSessionList(rw, req)
+	case req.Method == http.MethodPatch && strings.HasPrefix(path, "/sessions/"):
+		s.serveSessionPatch(rw, strings.TrimPrefix(path, "/sessions/"), content)
+	default:
+		writeJSON(rw, http.StatusNotFound, errorResponse("not_found", "resource not found"))
+	}
+}
+
+func (s *Server) serveSysinfo(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	info := *s.Sysinfo
+	s.mu.Unlock()
+
+	if isExpiredLicense(req.Context()) {
+		info.License.HasExpired = true
+		info.License.ExpiresTimestamp = s.now().Add(-24 * time.Hour).Unix()
+	}
+
+	writeJSON(rw, http.StatusOK, sysinfoWire{SysinfoResponse: &info, License: licenseWire(info.License)})
+}
+
+// sysinfoWire is the sysinfo response as sent by the API, with the license secrets in clear.
+type sysinfoWire struct {
+	*anydesk.SysinfoResponse
+	License licenseWire `json:"license"`
+}
+
+// licenseWire is the license without its redacting MarshalJSON.
+type licenseWire anydesk.License
+
+func (s *Server) serveClientList(rw http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	lq := parseListQuery(q)
+
+	s.mu.Lock()
+	list := filterClients(s.Clients, q, lq)
+	s.mu.Unlock()
+
+	start, end := lq.window(len(list))
+
+	resp := lq.paginated(len(list), start, end, list[start:end])
+	resp["online"] = q.Get("online") == "true"
+
+	writeJSON(rw, http.StatusOK, resp)
+}
+
+func (s *Server) serveClientDetail(rw http.ResponseWriter, id string) {
+	cid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeJSON(rw, http.StatusNotFound, errorResponse("not_found", "client not found"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.Clients {
+		if c.ClientID != cid {
+			continue
+		}
+
+		c.LastSessions = s.lastSessions(cid, 5)
+		writeJSON(rw, http.StatusOK, c)
+		return
+	}
+
+	writeJSON(rw, http.StatusNotFound, errorResponse("not_found", "client not found"))
+}
+
+// lastSessions returns up to max of the most recent sessions the given client was involved in.
+func (s *Server) lastSessions(cid int64, max int) []anydesk.SessionNode {
+	list := make([]anydesk.SessionNode, 0, max)
+
+	for i := len(s.Sessions) - 1; i >= 0 && len(list) < max; i-- {
+		n := s.Sessions[i]
+		if (n.Source != nil && n.Source.ClientID == cid) || (n.Target != nil && n.Target.ClientID == cid) {
+			list = append(list, n)
+		}
+	}
+
+	return list
+}
+
+func (s *Server) serveSessionList(rw http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	lq := parseListQuery(q)
+
+	s.mu.Lock()
+	list := filterSessions(s.Sessions, q, lq)
+	s.mu.Unlock()
+
+	start, end := lq.window(len(list))
+
+	writeJSON(rw, http.StatusOK, lq.paginated(len(list), start, end, list[start:end]))
+}
+
+func (s *Server) serveSessionPatch(rw http.ResponseWriter, sid string, content []byte) {
+	patch := struct {
+		Comment *string `json:"comment"`
+	}{}
+
+	if err := json.Unmarshal(content, &patch); err != nil {
+		writeJSON(rw, http.StatusBadRequest, errorResponse("bad_request", err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Sessions {
+		if s.Sessions[i].SessionID != sid {
+			continue
+		}
+
+		s.Sessions[i].Comment = ""
+		if patch.Comment != nil {
+			s.Sessions[i].Comment = *patch.Comment
+		}
+
+		if s.OnChange != nil {
+			s.OnChange()
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(rw, http.StatusNotFound, errorResponse("not_found", "session not found"))
+}
+
+func newSysinfo(licenseID string, apiPassword string) *anydesk.SysinfoResponse {
+	info := &anydesk.SysinfoResponse{
+		Name:       "AnyDesk REST",
+		APIVersion: "1.1",
+	}
+
+	info.License.ID = licenseID
+	info.License.APIPassword = apiPassword
+	info.License.Name = "anydesktest"
+	info.License.ExpiresTimestamp = time.Now().AddDate(1, 0, 0).Unix()
+	info.License.MaxClients = -1
+	info.License.MaxSessions = -1
+	info.License.MaxSessionTime = -1
+
+	return info
+}
+
+func errorResponse(code string, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"result": "error",
+		"code":   code,
+		"error":  msg,
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("could not encode response: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	rw.WriteHeader(status)
+
+	_, _ = rw.Write(data)
+}
+
+type contextKey int
+
+const (
+	clockSkewKey contextKey = iota
+	expiredLicenseKey
+)
+
+func clockSkew(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(clockSkewKey).(time.Duration)
+	return d
+}
+
+func isExpiredLicense(ctx context.Context) bool {
+	v, _ := ctx.Value(expiredLicenseKey).(bool)
+	return v
+}
@@ -0,0 +1,126 @@
+package anydesktest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a server populated with a small set of clients and sessions.
+func newTestServer() *Server {
+	s := NewServer("TEST_LICENSE", "TEST_PASSWORD")
+
+	s.Clients = []anydesk.ClientNode{
+		{ClientID: 100, Alias: "alpha@ad", ClientVersion: "5.5.3", Online: true, OnlineSinceSeconds: 60},
+		{ClientID: 200, Alias: "beta@ad", ClientVersion: "5.4.0", OnlineSinceSeconds: -1},
+	}
+
+	s.Sessions = []anydesk.SessionNode{
+		{
+			SessionID:      "S1",
+			Source:         &anydesk.ClientSlimNode{ClientID: 100},
+			Target:         &anydesk.ClientSlimNode{ClientID: 200},
+			StartTimestamp: 1590504626,
+			EndTimestamp:   1590504637,
+		},
+	}
+
+	return s
+}
+
+// signedRequest returns a signed http request against the given resource.
+func signedRequest(t *testing.T, api *anydesk.API, method string, resource string) *http.Request {
+	base := &anydesk.BaseRequest{
+		Method:    method,
+		Resource:  resource,
+		Timestamp: time.Now().Unix(),
+	}
+
+	req, err := base.GetHTTPRequest(api)
+	assert.NoError(t, err)
+
+	return req
+}
+
+func TestServer_Auth(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	resp, err := anydesk.NewAuthenticationRequest().Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("success", resp.Result)
+	a.Equal("TEST_LICENSE", resp.LicenseID)
+}
+
+func TestServer_BadCredentials(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	api := s.API()
+	api.APIPassword = "WRONG"
+
+	_, err := anydesk.NewAuthenticationRequest().Do(api)
+	assert.IsType(t, &anydesk.APIBadCredentialsError{}, err)
+}
+
+func TestServer_Sysinfo(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	resp, err := anydesk.NewSysinfoRequest().Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("1.1", resp.APIVersion)
+	a.Equal("TEST_LICENSE", resp.License.ID)
+	a.False(resp.License.HasExpired)
+
+	// the secrets are sent in clear, like the API does
+	_, password := resp.License.Reveal()
+	a.Equal("TEST_PASSWORD", password)
+}
+
+func TestServer_ClientList(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	resp, err := anydesk.NewClientListRequest(nil).Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal(int64(2), resp.Count)
+	a.Len(resp.List, 2)
+	a.Equal("beta@ad", resp.List[1].Alias)
+}
+
+func TestServer_ClientDetail(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	resp, err := anydesk.NewClientDetailRequest(200).Do(s.API())
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("5.4.0", resp.ClientVersion)
+	a.Len(resp.LastSessions, 1)
+	a.Equal("S1", resp.LastSessions[0].SessionID)
+
+	_, err = anydesk.NewClientDetailRequest(300).Do(s.API())
+	a.IsType(&anydesk.APINotFoundError{}, err)
+}
+
+func TestServer_SessionCommentChange(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	_, err := s.API().Do(anydesk.NewSessionCommentChangeRequest("S1", "TEST_COMMENT"))
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("TEST_COMMENT", s.Sessions[0].Comment)
+}
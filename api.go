@@ -8,11 +8,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,7 +36,8 @@ type APIRequest interface {
 	// is used to compose the API request signature.
 	GetRequestDetails() *BaseRequest
 
-	// Returns the http.Request that is composed, signed and ready to execute
+	// Returns the http.Request that is composed, signed and ready to execute.
+	// Called by API.Do for every attempt, see BaseRequest.GetHTTPRequest for the endpoint.
 	GetHTTPRequest(api *API) (req *http.Request, err error)
 }
 
@@ -56,9 +60,89 @@ type API struct {
 	// API endpoint to be used
 	APIEndpoint string `json:"api_endpoint"`
 
+	// Optional ordered list of endpoints for highly-available deployments, the first one is the primary.
+	// Takes precedence over APIEndpoint. See EndpointStatus for their health.
+	Endpoints []string `json:"api_endpoints,omitempty"`
+
+	// Interval in which an unhealthy primary endpoint is probed via "/auth", defaults to DefaultProbeInterval.
+	ProbeInterval time.Duration `json:"-"`
+
 	// The http client used for API requests.
 	// Can be used or overwritten for timeout and transport layer configuration.
-	HTTPClient *http.Client
+	HTTPClient *http.Client `json:"-"`
+
+	// Source of the current time, used for signing, caching, failover and the circuit breaker.
+	// Defaults to SystemClock.
+	Clock Clock `json:"-"`
+
+	// Optional rate limiter, consulted before every request sent to the API.
+	RateLimiter RateLimiter `json:"-"`
+
+	// Optional circuit breaker, failing requests fast while the API is unavailable.
+	CircuitBreaker *CircuitBreaker `json:"-"`
+
+	// Maximum size of a response body in bytes, larger responses fail with *APIResponseTooLargeError.
+	// Zero means no limit.
+	MaxResponseSize int64 `json:"-"`
+
+	// Optional cache for GET responses, see ResponseCache.
+	Cache *ResponseCache `json:"-"`
+
+	// Deduplicates identical GET requests in flight, so a single http request serves all callers.
+	// Each caller receives its own copy of the response.
+	CoalesceRequests bool `json:"-"`
+
+	// Optional recorder for all http exchanges, i.e. to hand them to the AnyDesk support.
+	// Works without SetDebug, see HARRecorder.
+	HAR *HARRecorder `json:"-"`
+
+	// Compares typed responses with the known schema and reports unknown, missing and deprecated
+	// fields in ResponseMeta.Drift, without failing the request. Streamed lists are not checked.
+	StrictDecoding bool `json:"-"`
+
+	// Optional callback for responses that differ from the known schema, see StrictDecoding.
+	OnSchemaDrift func(resource string, drift []SchemaDrift) `json:"-"`
+
+	// Optional TLS settings for enterprise on-premise endpoints, applied on top of the HTTPClient transport.
+	// Invalid options are reported as *TLSConfigError by the first request, as are transports other than
+	// *http.Transport, i.e. the cassette transports, which can wrap a transport built by TLSOptions.Transport.
+	TLS *TLSOptions `json:"-"`
+
+	// Holds the *apiState, kept behind a pointer so the API can be passed by value, i.e. to MarshalJSON.
+	state atomic.Value
+}
+
+// apiState contains the internal state of an API, shared by all requests.
+type apiState struct {
+	tlsMu     sync.Mutex
+	tlsClient *http.Client
+	tlsFor    [2]interface{}
+
+	health       endpointHealth
+	flights      flightGroup
+	capabilities Capabilities
+}
+
+// apiStateMu guards the creation of the state of zero value APIs.
+var apiStateMu sync.Mutex
+
+// internal returns the internal state of the API, created with the first call.
+func (api *API) internal() *apiState {
+	if s, ok := api.state.Load().(*apiState); ok {
+		return s
+	}
+
+	apiStateMu.Lock()
+	defer apiStateMu.Unlock()
+
+	if s, ok := api.state.Load().(*apiState); ok {
+		return s
+	}
+
+	s := &apiState{}
+	api.state.Store(s)
+
+	return s
 }
 
 // NewAPI returns an initialized AnyDesk API configuration used with a Professional license.
@@ -80,13 +164,154 @@ func (api *API) GetRequestToken(request *BaseRequest) string {
 }
 
 // Do will execute a given AnyDesk API request and return the plain json as string.
+// With multiple Endpoints, the request is signed and sent per attempt and fails over to
+// the next endpoint on connection errors and 5xx responses. Details about the http exchange
+// are available with the Meta of the request.
 func (api *API) Do(request APIRequest) (body []byte, err error) {
-	// Insert current timestamp so we can sign the request
-	request.GetRequestDetails().Timestamp = time.Now().Unix()
-
 	base := request.GetRequestDetails()
 
-	// Check if the request supports the
+	// The query is engraved into the resource for this execution only, so the request can be executed again
+	resource := base.Resource
+
+	defer func() {
+		base.Resource = resource
+	}()
+
+	if err = api.prepare(request); err != nil {
+		return
+	}
+
+	start := api.now()
+
+	var meta ResponseMeta
+
+	defer func() {
+		meta.Latency = api.now().Sub(start)
+		base.meta = &meta
+	}()
+
+	key, isGet := api.cacheKey(base)
+	cacheable := isGet && api.Cache != nil
+
+	if cacheable {
+		if cached, cachedMeta, ok := api.Cache.get(key, api.now(), false); ok {
+			meta = cachedMeta
+			meta.Attempts = 0
+			meta.CacheStatus = CacheHit
+			return cached, nil
+		}
+	}
+
+	var unavailable bool
+
+	if isGet && api.CoalesceRequests {
+		body, meta, unavailable, err = api.internal().flights.do(key, func() ([]byte, ResponseMeta, bool, error) {
+			return api.execute(request)
+		})
+	} else {
+		body, meta, unavailable, err = api.execute(request)
+	}
+
+	switch {
+	case api.Cache == nil:
+	case err == nil && cacheable:
+		api.Cache.set(key, resourcePath(base.Resource), body, meta, api.now())
+		meta.CacheStatus = CacheMiss
+	case err == nil && strings.EqualFold(base.Method, http.MethodPatch):
+		api.Cache.invalidate(api.cacheScope(), resourcePath(base.Resource))
+	case cacheable && unavailable && api.Cache.StaleIfError > 0:
+		if cached, cachedMeta, ok := api.Cache.get(key, api.now(), true); ok {
+			cachedMeta.Attempts = meta.Attempts
+			meta = cachedMeta
+			meta.CacheStatus = CacheStale
+			return cached, nil
+		}
+	}
+
+	return
+}
+
+// execute sends the prepared request, guarded by the circuit breaker and rate limiter.
+// unavailable reports whether the API could not be reached at all.
+func (api *API) execute(request APIRequest) (body []byte, meta ResponseMeta, unavailable bool, err error) {
+	// Fail fast while the API is known to be unavailable
+	if api.CircuitBreaker != nil {
+		api.CircuitBreaker.useClock(api.clock())
+
+		if err = api.CircuitBreaker.allow(api.now()); err != nil {
+			return nil, meta, true, err
+		}
+	}
+
+	// Wait for the rate limiter before signing, so the timestamp stays fresh
+	if api.RateLimiter != nil {
+		waitFor(api.RateLimiter, api.clock())
+	}
+
+	api.probePrimary()
+
+	var outcome sendOutcome
+
+	for _, endpoint := range api.internal().health.order(api.endpoints()) {
+		body, outcome, err = api.send(request, endpoint, &meta)
+		if outcome != sendFailover {
+			break
+		}
+
+		api.internal().health.markDown(endpoint, api.now())
+	}
+
+	// Local errors, i.e. invalid TLS options, say nothing about the health of the API
+	if outcome == sendLocalError {
+		if api.CircuitBreaker != nil {
+			api.CircuitBreaker.release()
+		}
+
+		return
+	}
+
+	unavailable = outcome == sendFailover
+
+	if !unavailable {
+		api.internal().health.markUp(meta.Endpoint)
+	}
+
+	// All failover attempts of a request count as a single outcome
+	if api.CircuitBreaker != nil {
+		api.CircuitBreaker.record(!unavailable, api.now())
+	}
+
+	return
+}
+
+// sendOutcome describes the result of a single attempt to send a request to an endpoint.
+type sendOutcome int
+
+const (
+	// sendDone indicates that the endpoint answered, successful or with a client error.
+	sendDone sendOutcome = iota
+
+	// sendFailover indicates that the endpoint failed and the next one should be attempted.
+	sendFailover
+
+	// sendLocalError indicates that the request could not be sent due to a local error,
+	// i.e. a configuration error, without reaching any endpoint.
+	sendLocalError
+)
+
+// prepare encodes the query and content of the request, which are part of the signature.
+func (api *API) prepare(request APIRequest) error {
+	if err := validate(request); err != nil {
+		return err
+	}
+
+	if r, ok := request.(capabilityRequirer); ok {
+		if err := api.internal().capabilities.require(r.requiredCapabilities()...); err != nil {
+			return err
+		}
+	}
+
+	base := request.GetRequestDetails()
 
 	// Ensure we encode the optional query parameters into the BaseRequest.Resource
 	// otherwise the signature will not match
@@ -97,50 +322,109 @@ func (api *API) Do(request APIRequest) (body []byte, err error) {
 	// Ensure we encode possible request content into json
 	content, err := json.Marshal(request)
 	if err != nil {
-		return
+		return err
 	}
 
 	base.Content = content
 
-	// Collect request body for debug
-	if isDebug {
+	// Collect request body for debug, also for the HAR recorder
+	if isDebug || api.HAR != nil {
 		d := request.GetDebug()
 		d.Available = true
 		d.RequestBody = content
 	}
 
-	// Create a clean request
+	return nil
+}
+
+// send signs the prepared request for the given endpoint and executes it, recording the exchange in meta.
+func (api *API) send(request APIRequest, endpoint string, meta *ResponseMeta) (body []byte, outcome sendOutcome, err error) {
+	client, err := api.client()
+	if err != nil {
+		outcome = sendLocalError
+		return
+	}
+
+	base := request.GetRequestDetails()
+
+	// Insert current timestamp so we can sign the request
+	base.Timestamp = api.now().Unix()
+
+	// Create a clean request through the interface, so custom requests can adjust it
+	base.endpoint = endpoint
 	r, err := request.GetHTTPRequest(api)
+	base.endpoint = ""
+
 	if err != nil {
+		outcome = sendLocalError
 		return
 	}
 
-	// Collect http request for debug
-	if isDebug {
+	// Collect http request for debug, also for the HAR recorder
+	collect := isDebug || api.HAR != nil
+
+	if collect {
 		d := request.GetDebug()
-		d.Request = r
+		d.Request = redactRequest(r)
 		d.RequestURL = r.URL
+		d.Response = nil
+		d.ResponseBody = nil
+
+		trace := newDebugTrace()
+		r = r.WithContext(httptrace.WithClientTrace(r.Context(), trace.clientTrace()))
+
+		defer func() {
+			d.Timings = trace.timings()
+
+			if api.HAR != nil {
+				api.HAR.add(d, err)
+			}
+		}()
 	}
 
-	resp, err := api.HTTPClient.Do(r)
+	meta.Attempts++
+	meta.Endpoint = endpoint
+
+	resp, err := client.Do(r)
 
 	if err != nil {
+		outcome = sendFailover
 		return
 	}
 
-	// Collect http response for debug
-	if isDebug {
+	// Always release the connection, so it can be reused by the next request
+	defer closeBody(resp.Body)
+
+	meta.StatusCode = resp.StatusCode
+	meta.Header = resp.Header
+	meta.FetchedAt = api.now()
+
+	// Collect http response for debug, without the body holding on to the connection
+	if collect {
 		d := request.GetDebug()
-		d.Response = resp
+		d.Response = detachResponse(resp, d.Request)
 	}
 
-	body, err = ioutil.ReadAll(resp.Body)
+	reader := api.limitBody(resp.Body)
+
+	// Successful streamed responses are decoded while they are read
+	if base.stream != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		err = base.stream(reader)
+		return
+	}
+
+	body, err = ioutil.ReadAll(reader)
 	if err != nil {
+		var tooLarge *APIResponseTooLargeError
+		if !errors.As(err, &tooLarge) {
+			outcome = sendFailover
+		}
+
 		return
 	}
 
 	// Collect response body for debug
-	if isDebug {
+	if collect {
 		d := request.GetDebug()
 		d.ResponseBody = body
 	}
@@ -156,6 +440,10 @@ func (api *API) Do(request APIRequest) (body []byte, err error) {
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if resp.StatusCode >= 500 {
+			outcome = sendFailover
+		}
+
 		err = errors.New(resp.Status)
 		return
 	}
@@ -163,29 +451,72 @@ func (api *API) Do(request APIRequest) (body []byte, err error) {
 	return
 }
 
-// DoPaginated will execute a given AnyDesk API request and return the plain json as string.
-// In addition to the simple API.Do it will engrave pagination options into the request.
-func (api *API) DoPaginated(request PaginatedAPIRequest) (body []byte, err error) {
-	// Copy the pagination into the base query details
-	p := request.GetPaginationOptions()
-	base := request.GetRequestDetails()
+// maxDrainSize is the amount of unread response body that is discarded to keep a connection reusable.
+// Connections with larger remainders are closed instead.
+const maxDrainSize = 64 << 10
+
+// closeBody drains the remaining body and closes it, which returns the connection to the pool.
+func closeBody(body io.ReadCloser) {
+	_, _ = io.CopyN(ioutil.Discard, body, maxDrainSize)
+	_ = body.Close()
+}
+
+// detachResponse returns a copy of the response that does not reference the connection.
+// The signed request is replaced by the given redacted one.
+func detachResponse(resp *http.Response, req *http.Request) *http.Response {
+	r := *resp
+	r.Body = http.NoBody
+	r.Request = req
+
+	return &r
+}
+
+// client returns the http client used for requests, with the TLS options applied.
+// The composed client is kept until either HTTPClient or TLS is replaced.
+func (api *API) client() (*http.Client, error) {
+	if api.TLS == nil {
+		return api.HTTPClient, nil
+	}
+
+	s := api.internal()
 
-	// Ensure the query parameters are available
-	if base.Query == nil {
-		base.Query = &url.Values{}
+	s.tlsMu.Lock()
+	defer s.tlsMu.Unlock()
+
+	if s.tlsClient != nil && s.tlsFor == [2]interface{}{api.HTTPClient, api.TLS} {
+		return s.tlsClient, nil
 	}
 
-	base.Query.Set("offset", strconv.FormatInt(p.Offset, 10))
-	base.Query.Set("limit", strconv.FormatInt(p.Limit, 10))
+	var base *http.Transport
 
-	if p.Sort != "" {
-		base.Query.Set("sort", p.Sort)
+	switch t := api.HTTPClient.Transport.(type) {
+	case nil:
+	case *http.Transport:
+		base = t
+	default:
+		return nil, &TLSConfigError{Field: "HTTPClient.Transport", Err: fmt.Errorf("can not apply TLS options to %T", t)}
 	}
 
-	if p.Order != "" {
-		base.Query.Set("order", string(p.Order))
+	transport, err := api.TLS.Transport(base)
+	if err != nil {
+		return nil, err
 	}
 
+	client := *api.HTTPClient
+	client.Transport = transport
+
+	s.tlsClient = &client
+	s.tlsFor = [2]interface{}{api.HTTPClient, api.TLS}
+
+	return s.tlsClient, nil
+}
+
+// DoPaginated will execute a given AnyDesk API request and return the plain json as string.
+// In addition to the simple API.Do it will engrave pagination options into the request.
+func (api *API) DoPaginated(request PaginatedAPIRequest) (body []byte, err error) {
+	// Copy the pagination into the base query details
+	paginate(request)
+
 	body, err = api.Do(request)
 	if err != nil {
 		return
@@ -215,7 +546,10 @@ type BaseRequest struct {
 	Timestamp int64       `json:"-"`
 	Content   []byte      `json:"-"`
 
-	debug *DebugInfo
+	debug    *DebugInfo
+	meta     *ResponseMeta
+	stream   func(r io.Reader) error
+	endpoint string
 }
 
 // GetRequestDetails will return the base request details.
@@ -237,11 +571,20 @@ func (r *BaseRequest) GetRequestString() string {
 	return fmt.Sprintf("%s\n%s\n%d\n%s", strings.ToUpper(r.Method), r.Resource, r.Timestamp, r.GetContentHash())
 }
 
-// GetHTTPRequest will return the prepared HTTP request that can be used by a http.Client
+// GetHTTPRequest will return the prepared HTTP request that can be used by a http.Client.
+// API.Do calls it once per attempt, targeting the endpoint of the attempt, otherwise the primary endpoint is used.
 func (r *BaseRequest) GetHTTPRequest(api *API) (req *http.Request, err error) {
-	endpoint := fmt.Sprintf("%s%s", api.APIEndpoint, r.Resource)
+	endpoint := r.endpoint
+	if endpoint == "" {
+		endpoint = api.endpoints()[0]
+	}
+
+	return r.newHTTPRequest(api, endpoint)
+}
 
-	req, err = http.NewRequest(r.Method, endpoint, bytes.NewBuffer(r.Content))
+// newHTTPRequest returns the request signed for the given endpoint.
+func (r *BaseRequest) newHTTPRequest(api *API, endpoint string) (req *http.Request, err error) {
+	req, err = http.NewRequest(r.Method, endpoint+r.Resource, bytes.NewBuffer(r.Content))
 	if err != nil {
 		return
 	}
@@ -1,12 +1,17 @@
 package anydesk
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 )
@@ -115,3 +120,59 @@ func ExampleNewAPI() {
 
 	fmt.Printf("Status: %s, License: %s", response.Result, response.LicenseID)
 }
+
+func TestApi_ConnectionReuse(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+
+	data, err := ioutil.ReadFile("./_tests/session_list.json")
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("status") {
+		case "404":
+			rw.WriteHeader(http.StatusNotFound)
+		case "500":
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+
+		_, _ = rw.Write(data)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	// the first request opens the connection and its goroutines
+	_, err = api.Do(&BaseRequest{Method: "GET", Resource: "/sessions"})
+	assert.NoError(t, err)
+
+	goroutines := runtime.NumGoroutine()
+
+	for i := 0; i < 2000; i++ {
+		q := &url.Values{}
+		q.Set("status", []string{"200", "404", "500"}[i%3])
+
+		_, _ = api.Do(&BaseRequest{Method: "GET", Resource: "/sessions", Query: q})
+
+		// streams stopped early leave parts of the body unread
+		req := NewSessionListRequest(nil)
+		_, err := req.Each(api, func(node *SessionNode) error {
+			return errors.New("stop")
+		})
+		assert.Error(t, err)
+	}
+
+	mu.Lock()
+	assert.Equal(t, 1, conns)
+	mu.Unlock()
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines+10)
+}
@@ -1,9 +1,5 @@
 package anydesk
 
-import (
-	"encoding/json"
-)
-
 // AuthenticationRequest is used to read the "/auth" API resource.
 type AuthenticationRequest struct {
 	*BaseRequest
@@ -14,11 +10,13 @@ func (req *AuthenticationRequest) Do(api *API) (r *AuthenticationResponse, err e
 	r = newAuthenticationResponse()
 
 	body, err := api.Do(req)
+	r.Meta = req.Meta()
+
 	if err != nil {
 		return
 	}
 
-	err = json.Unmarshal(body, r)
+	err = api.decode(req, body, r)
 	if err != nil {
 		return
 	}
@@ -38,8 +36,11 @@ func NewAuthenticationRequest() *AuthenticationRequest {
 
 // AuthenticationResponse contains all available fields returned by the `/auth` API call.
 type AuthenticationResponse struct {
+	// Details about the http exchange.
+	Meta *ResponseMeta `json:"-"`
+
 	// Status result, should be "success".
-	Result string `json:"result"`
+	Result string `json:"result" schema:"required"`
 
 	// The humen readable error message.
 	Error string `json:"error"`
@@ -0,0 +1,170 @@
+package anydesk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchWorkers is the default number of concurrent requests of a batch.
+const DefaultBatchWorkers = 4
+
+// BatchProgress describes the progress of a batch after a single item finished.
+type BatchProgress struct {
+	// Number of finished items, including the current one.
+	Done int
+
+	// Total number of items of the batch.
+	Total int
+
+	// ID of the client that just finished.
+	ClientID int64
+
+	// Error of the current item, nil on success.
+	Err error
+}
+
+// ClientDetailBatchRequest fetches the details of multiple clients, i.e. to get their last sessions
+// which are not part of the client list. Requests are sent by a pool of workers and are subject to
+// the rate limiter of the API.
+type ClientDetailBatchRequest struct {
+	// IDs of the clients to fetch, duplicates are fetched once.
+	ClientIDs []int64
+
+	// Number of concurrent requests, defaults to DefaultBatchWorkers.
+	Workers int
+
+	// Optional callback, called after each client finished. Calls are serialized.
+	OnProgress func(p BatchProgress)
+}
+
+// NewClientDetailBatchRequest returns a batch request for the details of the given clients.
+func NewClientDetailBatchRequest(clientIDs []int64) *ClientDetailBatchRequest {
+	return &ClientDetailBatchRequest{
+		ClientIDs: clientIDs,
+		Workers:   DefaultBatchWorkers,
+	}
+}
+
+// NewClientDetailBatchRequestFromList returns a batch request for the details of all listed clients.
+func NewClientDetailBatchRequestFromList(list *ClientListResponse) *ClientDetailBatchRequest {
+	ids := make([]int64, len(list.List))
+	for i, c := range list.List {
+		ids[i] = c.ClientID
+	}
+
+	return NewClientDetailBatchRequest(ids)
+}
+
+// BatchResult contains the outcome of a ClientDetailBatchRequest.
+type BatchResult struct {
+	// Successfully fetched client details by client ID.
+	Results map[int64]*ClientDetailResponse
+
+	// Errors of the failed clients by client ID.
+	Errors map[int64]error
+}
+
+// BatchError will be returned by batch requests with failed items.
+// The successful items are still available in the BatchResult.
+type BatchError struct {
+	Errors map[int64]error
+}
+
+func (e *BatchError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	ids := make([]int64, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = fmt.Sprintf("%d: %s", id, e.Errors[id])
+	}
+
+	return fmt.Sprintf("%d failed: %s", len(ids), strings.Join(list, ", "))
+}
+
+// Do will execute the batch against the given API. The result is always returned, err is a
+// *BatchError if at least one client failed.
+func (req *ClientDetailBatchRequest) Do(api *API) (r *BatchResult, err error) {
+	r = &BatchResult{
+		Results: map[int64]*ClientDetailResponse{},
+		Errors:  map[int64]error{},
+	}
+
+	ids := make([]int64, 0, len(req.ClientIDs))
+	seen := make(map[int64]bool, len(req.ClientIDs))
+
+	for _, id := range req.ClientIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	workers := req.Workers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+
+	if workers > len(ids) {
+		workers = len(ids)
+	}
+
+	queue := make(chan int64)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for id := range queue {
+				resp, err := NewClientDetailRequest(id).Do(api)
+
+				mu.Lock()
+
+				if err != nil {
+					r.Errors[id] = err
+				} else {
+					r.Results[id] = resp
+				}
+
+				if req.OnProgress != nil {
+					req.OnProgress(BatchProgress{
+						Done:     len(r.Results) + len(r.Errors),
+						Total:    len(ids),
+						ClientID: id,
+						Err:      err,
+					})
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		queue <- id
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if len(r.Errors) > 0 {
+		err = &BatchError{Errors: r.Errors}
+	}
+
+	return
+}
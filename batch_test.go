@@ -0,0 +1,80 @@
+package anydesk
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientDetailBatchRequest(t *testing.T) {
+	var active, maxActive, calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		cid := strings.TrimPrefix(req.URL.Path, "/clients/")
+		if cid == "3" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"cid": %s, "alias": "client-%s", "online": true}`, cid, cid)
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	var progress []BatchProgress
+
+	req := NewClientDetailBatchRequest([]int64{1, 2, 3, 4, 5, 2})
+	req.Workers = 2
+	req.OnProgress = func(p BatchProgress) {
+		progress = append(progress, p)
+	}
+
+	r, err := req.Do(api)
+
+	// partial failures keep the successful results
+	assert.IsType(t, &BatchError{}, err)
+	assert.EqualError(t, err, "1 failed: 3: not found")
+	assert.Len(t, r.Results, 4)
+	assert.Len(t, r.Errors, 1)
+	assert.Equal(t, "client-5", r.Results[5].Alias)
+	assert.IsType(t, &APINotFoundError{}, r.Errors[3])
+
+	assert.Equal(t, int32(5), calls, "duplicates are fetched once")
+	assert.Equal(t, int32(2), maxActive)
+
+	assert.Len(t, progress, 5)
+	assert.Equal(t, 5, progress[4].Done)
+	assert.Equal(t, 5, progress[4].Total)
+}
+
+func TestNewClientDetailBatchRequestFromList(t *testing.T) {
+	list := &ClientListResponse{List: []ClientNode{{ClientID: 1}, {ClientID: 2}}}
+
+	req := NewClientDetailBatchRequestFromList(list)
+	assert.Equal(t, []int64{1, 2}, req.ClientIDs)
+	assert.Equal(t, DefaultBatchWorkers, req.Workers)
+
+	r, err := NewClientDetailBatchRequest(nil).Do(NewAPI("", ""))
+	assert.NoError(t, err)
+	assert.Empty(t, r.Results)
+}
@@ -0,0 +1,231 @@
+package anydesk
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheEntries is the default maximum number of responses kept by a ResponseCache.
+const DefaultCacheEntries = 1000
+
+// CacheStatus describes whether a response was served from the cache.
+type CacheStatus string
+
+const (
+	// CacheNone indicates that the request was not cacheable or no cache is configured.
+	CacheNone CacheStatus = ""
+
+	// CacheMiss indicates that the response was fetched from the API and stored in the cache.
+	CacheMiss CacheStatus = "miss"
+
+	// CacheHit indicates that a fresh response was served from the cache.
+	CacheHit CacheStatus = "hit"
+
+	// CacheStale indicates that an expired response was served, because the API was unavailable.
+	CacheStale CacheStatus = "stale"
+)
+
+// ResponseCache keeps successful GET responses, keyed by license ID, endpoints, method, resource and query.
+// A single cache can therefore be shared by APIs of different licenses.
+// Assign it to API.Cache to enable it:
+//
+//   api.Cache = anydesk.NewResponseCache(time.Minute)
+//   api.Cache.TTLs = map[string]time.Duration{
+//       "/sysinfo":  5 * time.Minute,
+//       "/sessions": 0, // never cached
+//   }
+//   api.Cache.StaleIfError = time.Hour
+//
+// A successful PATCH invalidates cached responses of the same resource and its collection,
+// i.e. "/sessions/123" invalidates "/sessions/123" and all "/sessions" lists, of the same license and endpoints.
+type ResponseCache struct {
+	// TTL of resources without a matching entry in TTLs.
+	DefaultTTL time.Duration
+
+	// TTLs by resource prefix, the longest matching prefix wins. A TTL of zero disables caching.
+	TTLs map[string]time.Duration
+
+	// Maximum number of cached responses, defaults to DefaultCacheEntries.
+	MaxEntries int
+
+	// Maximum total size of cached response bodies in bytes, zero means no limit.
+	MaxBytes int
+
+	// Time after expiry in which a response is still served if the API is unreachable,
+	// i.e. on connection errors, 5xx responses or an open circuit breaker. Zero disables it.
+	StaleIfError time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List
+	size    int
+}
+
+type cacheEntry struct {
+	key     string
+	path    string
+	body    []byte
+	meta    ResponseMeta
+	expires time.Time
+}
+
+// NewResponseCache returns a response cache with the given default TTL.
+func NewResponseCache(defaultTTL time.Duration) *ResponseCache {
+	return &ResponseCache{DefaultTTL: defaultTTL}
+}
+
+// TTL returns the time to live for responses of the given resource path.
+func (c *ResponseCache) TTL(path string) time.Duration {
+	ttl, match := c.DefaultTTL, -1
+
+	for prefix, t := range c.TTLs {
+		if strings.HasPrefix(path, prefix) && len(prefix) > match {
+			ttl, match = t, len(prefix)
+		}
+	}
+
+	return ttl
+}
+
+// Len returns the number of cached responses.
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Purge removes all cached responses.
+func (c *ResponseCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+	c.lru.Init()
+	c.size = 0
+}
+
+// Invalidate removes the cached responses of the given resource path and its collection,
+// for all APIs sharing the cache. A PATCH through an API only invalidates the responses of its
+// license and endpoints.
+func (c *ResponseCache) Invalidate(path string) {
+	c.invalidate("", path)
+}
+
+// invalidate removes the cached responses of the resource path and its collection whose key starts with the scope.
+func (c *ResponseCache) invalidate(scope string, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	collection := path
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		collection = path[:i]
+	}
+
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+
+		entry := e.Value.(*cacheEntry)
+
+		if (entry.path == path || entry.path == collection) && strings.HasPrefix(entry.key, scope) {
+			c.remove(e)
+		}
+
+		e = next
+	}
+}
+
+// cacheKey returns the key of the prepared request and whether it may be cached.
+// The key contains the license ID and endpoints, so a cache can be shared by APIs of different licenses.
+func (api *API) cacheKey(base *BaseRequest) (key string, ok bool) {
+	if !strings.EqualFold(base.Method, http.MethodGet) || base.stream != nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s%s %s", api.cacheScope(), http.MethodGet, base.Resource), true
+}
+
+// cacheScope returns the prefix of the cache keys of the API, containing its license ID and endpoints.
+func (api *API) cacheScope() string {
+	return fmt.Sprintf("%s %s ", api.LicenseID, strings.Join(api.endpoints(), ","))
+}
+
+// resourcePath returns the resource without query.
+func resourcePath(resource string) string {
+	if i := strings.IndexByte(resource, '?'); i >= 0 {
+		return resource[:i]
+	}
+
+	return resource
+}
+
+// get returns a copy of the cached body and meta, fresh ones only unless stale is set.
+func (c *ResponseCache) get(key string, now time.Time, stale bool) ([]byte, ResponseMeta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, ResponseMeta{}, false
+	}
+
+	entry := e.Value.(*cacheEntry)
+
+	if now.After(entry.expires) && (!stale || now.After(entry.expires.Add(c.StaleIfError))) {
+		return nil, ResponseMeta{}, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	return append([]byte(nil), entry.body...), entry.meta.clone(), true
+}
+
+func (c *ResponseCache) set(key string, path string, body []byte, meta ResponseMeta, now time.Time) {
+	ttl := c.TTL(path)
+	if ttl <= 0 || (c.MaxBytes > 0 && len(body) > c.MaxBytes) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+	}
+
+	entry := &cacheEntry{
+		key:     key,
+		path:    path,
+		body:    append([]byte(nil), body...),
+		meta:    meta.clone(),
+		expires: now.Add(ttl),
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += len(body)
+
+	maxEntries := c.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+
+	for c.lru.Len() > maxEntries || (c.MaxBytes > 0 && c.size > c.MaxBytes) {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *ResponseCache) remove(e *list.Element) {
+	entry := e.Value.(*cacheEntry)
+
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.body)
+}
@@ -0,0 +1,223 @@
+package anydesk
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache_TTL(t *testing.T) {
+	c := NewResponseCache(time.Minute)
+	c.TTLs = map[string]time.Duration{
+		"/clients":   time.Second,
+		"/clients/1": time.Hour,
+		"/sessions":  0,
+	}
+
+	assert.Equal(t, time.Minute, c.TTL("/sysinfo"))
+	assert.Equal(t, time.Second, c.TTL("/clients"))
+	assert.Equal(t, time.Hour, c.TTL("/clients/100"))
+	assert.Equal(t, time.Duration(0), c.TTL("/sessions"))
+}
+
+func TestResponseCache_Limits(t *testing.T) {
+	now := time.Now()
+
+	c := NewResponseCache(time.Minute)
+	c.MaxEntries = 2
+
+	c.set("GET /a", "/a", []byte("a"), ResponseMeta{}, now)
+	c.set("GET /b", "/b", []byte("b"), ResponseMeta{}, now)
+
+	// reading moves an entry to the front, so "/b" is evicted
+	_, _, ok := c.get("GET /a", now, false)
+	assert.True(t, ok)
+
+	c.set("GET /c", "/c", []byte("c"), ResponseMeta{}, now)
+	assert.Equal(t, 2, c.Len())
+
+	_, _, ok = c.get("GET /b", now, false)
+	assert.False(t, ok)
+
+	c = NewResponseCache(time.Minute)
+	c.MaxBytes = 5
+
+	c.set("GET /a", "/a", []byte("aaa"), ResponseMeta{}, now)
+	c.set("GET /b", "/b", []byte("bbb"), ResponseMeta{}, now)
+	c.set("GET /c", "/c", []byte("cccccc"), ResponseMeta{}, now)
+	assert.Equal(t, 1, c.Len())
+
+	_, _, ok = c.get("GET /b", now, false)
+	assert.True(t, ok)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestResponseCache_Expiry(t *testing.T) {
+	now := time.Now()
+
+	c := NewResponseCache(time.Minute)
+	c.StaleIfError = time.Hour
+	c.set("GET /a", "/a", []byte("a"), ResponseMeta{}, now)
+
+	_, _, ok := c.get("GET /a", now.Add(2*time.Minute), false)
+	assert.False(t, ok)
+
+	body, _, ok := c.get("GET /a", now.Add(2*time.Minute), true)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), body)
+
+	_, _, ok = c.get("GET /a", now.Add(2*time.Hour), true)
+	assert.False(t, ok)
+}
+
+func TestResponseCache_Invalidate(t *testing.T) {
+	now := time.Now()
+
+	c := NewResponseCache(time.Minute)
+	c.set("GET /sessions?limit=10", "/sessions", []byte("list"), ResponseMeta{}, now)
+	c.set("GET /sessions/1", "/sessions/1", []byte("1"), ResponseMeta{}, now)
+	c.set("GET /sessions/2", "/sessions/2", []byte("2"), ResponseMeta{}, now)
+	c.set("GET /sysinfo", "/sysinfo", []byte("sysinfo"), ResponseMeta{}, now)
+
+	c.Invalidate("/sessions/1")
+
+	assert.Equal(t, 2, c.Len())
+
+	_, _, ok := c.get("GET /sessions/2", now, false)
+	assert.True(t, ok)
+}
+
+func TestAPI_Do_Cache(t *testing.T) {
+	var status, calls int32 = 200, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(int(atomic.LoadInt32(&status)))
+
+		if req.Method == http.MethodGet {
+			data, err := ioutil.ReadFile("./_tests/sysinfo.json")
+			assert.NoError(t, err)
+
+			_, _ = rw.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.Cache = NewResponseCache(time.Minute)
+	api.Cache.StaleIfError = time.Hour
+	api.Clock = newTestClock()
+
+	req := NewSysinfoRequest()
+	_, err := req.Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheMiss, req.CacheStatus())
+
+	req = NewSysinfoRequest()
+	resp, err := req.Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheHit, req.CacheStatus())
+	assert.Equal(t, "AnyDesk REST", resp.Name)
+	assert.Equal(t, int32(1), calls)
+
+	// expired responses are served while the API fails
+	api.Cache.Purge()
+
+	_, err = NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	api.Clock.Sleep(2 * time.Minute)
+
+	req = NewSysinfoRequest()
+	resp, err = req.Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStale, req.CacheStatus())
+	assert.Equal(t, "AnyDesk REST", resp.Name)
+
+	// client errors are returned as they are
+	atomic.StoreInt32(&status, http.StatusNotFound)
+
+	req = NewSysinfoRequest()
+	_, err = req.Do(api)
+	assert.IsType(t, &APINotFoundError{}, err)
+	assert.Equal(t, CacheNone, req.CacheStatus())
+}
+
+func TestAPI_Do_CacheInvalidation(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		if req.Method == http.MethodGet {
+			data, err := ioutil.ReadFile("./_tests/session_list.json")
+			assert.NoError(t, err)
+
+			_, _ = rw.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.Cache = NewResponseCache(time.Minute)
+
+	_, err := NewSessionListRequest(nil).Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, api.Cache.Len())
+
+	assert.NoError(t, NewSessionCommentChangeRequest("SESSION1", "changed").Do(api))
+	assert.Equal(t, 0, api.Cache.Len())
+
+	_, err = NewSessionListRequest(nil).Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestAPI_Do_CacheSharedByLicenses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		license := strings.Split(strings.TrimPrefix(req.Header.Get("Authorization"), "AD "), ":")[0]
+		_, _ = fmt.Fprintf(rw, `{"license-id": %q}`, license)
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(time.Minute)
+
+	first := NewAPITestClient(t, server, "LICENSE1", "")
+	first.Cache = cache
+
+	second := NewAPITestClient(t, server, "LICENSE2", "")
+	second.Cache = cache
+
+	resp, err := NewAuthenticationRequest().Do(first)
+	assert.NoError(t, err)
+	assert.Equal(t, "LICENSE1", resp.LicenseID)
+
+	req := NewAuthenticationRequest()
+	resp, err = req.Do(second)
+	assert.NoError(t, err)
+	assert.Equal(t, "LICENSE2", resp.LicenseID)
+	assert.Equal(t, CacheMiss, req.CacheStatus())
+	assert.Equal(t, 2, cache.Len())
+
+	// changes through one license keep the responses of the other
+	_, err = first.Do(&BaseRequest{Method: http.MethodPatch, Resource: "/auth"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	req = NewAuthenticationRequest()
+	_, err = req.Do(second)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheHit, req.CacheStatus())
+
+	cache.Invalidate("/auth")
+	assert.Equal(t, 0, cache.Len())
+}
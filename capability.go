@@ -0,0 +1,171 @@
+package anydesk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Capability is a feature of the AnyDesk API that is not available on all installations.
+type Capability string
+
+// Capabilities of the AnyDesk API, see Capabilities.
+const (
+	CapabilityClientList             Capability = "client-list"
+	CapabilityClientDetail           Capability = "client-detail"
+	CapabilityClientOnlineFilter     Capability = "client-online-filter"
+	CapabilitySessionList            Capability = "session-list"
+	CapabilitySessionComment         Capability = "session-comment"
+	CapabilitySessionDirectionFilter Capability = "session-direction-filter"
+	CapabilitySessionTimeFilter      Capability = "session-time-filter"
+)
+
+// KnownCapabilities contains the capabilities required by the requests of this package.
+var KnownCapabilities = []Capability{
+	CapabilityClientList,
+	CapabilityClientDetail,
+	CapabilityClientOnlineFilter,
+	CapabilitySessionList,
+	CapabilitySessionComment,
+	CapabilitySessionDirectionFilter,
+	CapabilitySessionTimeFilter,
+}
+
+// ErrUnsupported matches the errors returned for requests the connected API does not support:
+//
+//   if errors.Is(err, anydesk.ErrUnsupported) { ... }
+var ErrUnsupported = errors.New("not supported by the API")
+
+// APIUnsupportedError will be returned by requests that need a capability the API does not support.
+type APIUnsupportedError struct {
+	Capability Capability
+}
+
+func (e *APIUnsupportedError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%s is %s", e.Capability, ErrUnsupported)
+}
+
+// Is reports whether the target is ErrUnsupported.
+func (e *APIUnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// Capabilities records the features supported by the connected API. AnyDesk does not document
+// which API version introduced a feature, so all capabilities count as supported until they are
+// disabled with Set, i.e. by tooling that knows an installation based on its APIVersion.
+type Capabilities struct {
+	mu         sync.Mutex
+	apiVersion string
+	overrides  map[Capability]bool
+}
+
+// APIVersion returns the version reported by the last "/sysinfo" call, empty before.
+func (c *Capabilities) APIVersion() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.apiVersion
+}
+
+// Supports reports whether the API supports the capability.
+// Capabilities count as supported unless disabled with Set.
+func (c *Capabilities) Supports(capability Capability) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.supports(capability)
+}
+
+func (c *Capabilities) supports(capability Capability) bool {
+	if supported, ok := c.overrides[capability]; ok {
+		return supported
+	}
+
+	return true
+}
+
+// Set records whether the API supports the given capability, i.e. for installations lacking a feature.
+func (c *Capabilities) Set(capability Capability, supported bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.overrides == nil {
+		c.overrides = map[Capability]bool{}
+	}
+
+	c.overrides[capability] = supported
+}
+
+// Reset removes all overrides and the recorded API version.
+func (c *Capabilities) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overrides = nil
+	c.apiVersion = ""
+}
+
+// Matrix returns whether each known capability is supported.
+func (c *Capabilities) Matrix() map[Capability]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := map[Capability]bool{}
+
+	for _, capability := range KnownCapabilities {
+		m[capability] = c.supports(capability)
+	}
+
+	for capability := range c.overrides {
+		m[capability] = c.supports(capability)
+	}
+
+	return m
+}
+
+// record sets the API version reported by "/sysinfo".
+func (c *Capabilities) record(apiVersion string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.apiVersion = apiVersion
+}
+
+// require returns *APIUnsupportedError for the first capability that is not supported.
+func (c *Capabilities) require(capabilities ...Capability) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, capability := range capabilities {
+		if c.supports(capability) {
+			continue
+		}
+
+		return &APIUnsupportedError{Capability: capability}
+	}
+
+	return nil
+}
+
+// capabilityRequirer is implemented by requests that depend on capabilities of the API.
+type capabilityRequirer interface {
+	requiredCapabilities() []Capability
+}
+
+// Capabilities returns the capability matrix of the API, see Capabilities.
+func (api *API) Capabilities() *Capabilities {
+	return &api.internal().capabilities
+}
+
+// DiscoverCapabilities fetches "/sysinfo" to record the API version and returns the capability matrix.
+func (api *API) DiscoverCapabilities() (*Capabilities, error) {
+	if _, err := NewSysinfoRequest().Do(api); err != nil {
+		return nil, err
+	}
+
+	return api.Capabilities(), nil
+}
@@ -0,0 +1,80 @@
+package anydesk
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCapabilities_Supports(t *testing.T) {
+	c := &Capabilities{}
+
+	// without overrides everything counts as supported, independent of the version
+	c.record("0.9")
+	assert.Equal(t, "0.9", c.APIVersion())
+	assert.True(t, c.Supports(CapabilityClientList))
+	assert.True(t, c.Supports("unknown"))
+	assert.NoError(t, c.require(KnownCapabilities...))
+
+	c.Set(CapabilityClientDetail, false)
+	c.Set("custom", false)
+
+	assert.False(t, c.Supports(CapabilityClientDetail))
+	assert.Equal(t, &APIUnsupportedError{Capability: CapabilityClientDetail}, c.require(CapabilityClientList, CapabilityClientDetail))
+
+	m := c.Matrix()
+	assert.True(t, m[CapabilityClientList])
+	assert.False(t, m[CapabilityClientDetail])
+	assert.False(t, m["custom"])
+	assert.Len(t, m, len(KnownCapabilities)+1)
+
+	c.Reset()
+	assert.Equal(t, "", c.APIVersion())
+	assert.True(t, c.Supports(CapabilityClientDetail))
+}
+
+func TestAPI_Capabilities(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		if strings.HasPrefix(req.URL.Path, "/sysinfo") {
+			_, _ = rw.Write([]byte(`{"api-ver": "0.9"}`))
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"count": 0, "selected": 0, "offset": 0, "limit": -1, "list": []}`))
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	c, err := api.DiscoverCapabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.9", c.APIVersion())
+
+	// the tooling knows the installation lacks the feature
+	c.Set(CapabilitySessionTimeFilter, false)
+
+	_, err = NewSessionListRequest(&SessionListSearch{TimeFrom: time.Now()}).Do(api)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.Equal(t, &APIUnsupportedError{Capability: CapabilitySessionTimeFilter}, err)
+	assert.EqualError(t, err, "session-time-filter is not supported by the API")
+	assert.Equal(t, int32(1), calls)
+
+	api.Capabilities().Set(CapabilitySessionTimeFilter, true)
+	api.Capabilities().Set(CapabilityClientList, false)
+
+	_, err = NewSessionListRequest(&SessionListSearch{TimeFrom: time.Now()}).Do(api)
+	assert.IsType(t, &APINoResultsError{}, err)
+
+	_, err = NewClientListRequest(nil).Do(api)
+	assert.EqualError(t, err, "client-list is not supported by the API")
+	assert.Equal(t, int32(2), calls)
+}
@@ -0,0 +1,132 @@
+// Package cassette provides a http.RoundTripper that records AnyDesk API traffic into
+// cassette files and replays it later, i.e. to run tests in CI without credentials.
+//
+// Record the traffic once against the real API:
+//
+//   recorder := cassette.NewRecorder("_tests/cassettes/sysinfo.json", nil)
+//   api.HTTPClient.Transport = recorder
+//
+// Replay it afterwards:
+//
+//   replayer, err := cassette.NewReplayer("_tests/cassettes/sysinfo.json")
+//   api.HTTPClient.Transport = replayer
+//
+// The API applies TLSOptions only to a *http.Transport, so the cassette transports can not be
+// combined with API.TLS. Record with a transport built from the options instead:
+//
+//   transport, err := tlsOptions.Transport(nil)
+//   api.HTTPClient.Transport = cassette.NewRecorder("_tests/cassettes/sysinfo.json", transport)
+//
+// Recorded requests do not contain the "Authorization" header and secrets in response bodies
+// are scrubbed. Requests are matched on method, resource and query, so the changing timestamp
+// and signature of every request does not matter.
+package cassette
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/adrianrudnik/anydesk/internal/redact"
+)
+
+// Redacted replaces scrubbed secrets in recorded bodies.
+const Redacted = redact.Redacted
+
+// DefaultScrubFields contains the JSON properties scrubbed from recorded bodies.
+var DefaultScrubFields = redact.Fields
+
+// Cassette contains a list of recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction contains a single recorded request and response pair.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request contains the recorded request details.
+type Request struct {
+	// Request method, i.e. "GET".
+	Method string `json:"method"`
+
+	// Requested resource without query, i.e. "/clients".
+	Resource string `json:"resource"`
+
+	// Request query parameters.
+	Query url.Values `json:"query,omitempty"`
+
+	// Request body as sent.
+	Body string `json:"body,omitempty"`
+}
+
+// Response contains the recorded response details.
+type Response struct {
+	// Response status code.
+	StatusCode int `json:"status_code"`
+
+	// Response headers.
+	Header http.Header `json:"header,omitempty"`
+
+	// Response body, with secrets scrubbed.
+	Body string `json:"body"`
+}
+
+// Load reads the cassette from the given file.
+func Load(path string) (c *Cassette, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	c = &Cassette{}
+	err = json.Unmarshal(data, c)
+
+	return
+}
+
+// Save writes the cassette to the given file, creating missing directories.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// matches checks if the recorded request matches the given http request.
+func (r *Request) matches(req *http.Request) bool {
+	if r.Method != req.Method || r.Resource != req.URL.Path {
+		return false
+	}
+
+	q := req.URL.Query()
+	if len(q) != len(r.Query) {
+		return false
+	}
+
+	for k, v := range q {
+		rv := r.Query[k]
+		if len(rv) != len(v) {
+			return false
+		}
+
+		for i := range v {
+			if rv[i] != v[i] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
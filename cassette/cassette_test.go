@@ -0,0 +1,54 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassette_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "nested", "test.json")
+
+	c := &Cassette{
+		Interactions: []*Interaction{
+			{
+				Request:  Request{Method: "GET", Resource: "/clients", Query: url.Values{"limit": {"10"}}},
+				Response: Response{StatusCode: 200, Body: `{}`},
+			},
+		},
+	}
+
+	a := assert.New(t)
+	a.NoError(c.Save(path))
+
+	loaded, err := Load(path)
+	a.NoError(err)
+	a.Equal(c, loaded)
+}
+
+func TestRequest_Matches(t *testing.T) {
+	r := &Request{Method: "GET", Resource: "/sessions", Query: url.Values{"cid": {"1"}, "limit": {"10"}}}
+
+	match := func(method string, target string) bool {
+		req, err := http.NewRequest(method, "http://127.0.0.1"+target, nil)
+		assert.NoError(t, err)
+
+		return r.matches(req)
+	}
+
+	a := assert.New(t)
+	a.True(match("GET", "/sessions?limit=10&cid=1"))
+	a.False(match("PATCH", "/sessions?limit=10&cid=1"))
+	a.False(match("GET", "/sessions?limit=10"))
+	a.False(match("GET", "/sessions?limit=10&cid=2"))
+	a.False(match("GET", "/clients?limit=10&cid=1"))
+}
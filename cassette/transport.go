@@ -0,0 +1,199 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/adrianrudnik/anydesk/internal/redact"
+)
+
+// Recorder is a http.RoundTripper that records all requests and responses into a cassette file.
+type Recorder struct {
+	// Path of the cassette file, written after every interaction.
+	Path string
+
+	// Transport used to execute the requests.
+	Transport http.RoundTripper
+
+	// JSON properties scrubbed from the recorded bodies, defaults to DefaultScrubFields.
+	ScrubFields []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder returns a recorder that writes to the given cassette file.
+// If transport is nil, http.DefaultTransport is used.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Recorder{
+		Path:        path,
+		Transport:   transport,
+		ScrubFields: DefaultScrubFields,
+		cassette:    &Cassette{},
+	}
+}
+
+// RoundTrip executes the request and records it together with its response.
+// The given request is not modified, a clone with the recorded body is executed.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		var err error
+
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: Request{
+			Method:   req.Method,
+			Resource: req.URL.Path,
+			Query:    req.URL.Query(),
+			Body:     string(redact.JSON(reqBody, r.ScrubFields)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(redact.JSON(respBody, r.ScrubFields)),
+		},
+	}
+
+	if len(i.Request.Query) == 0 {
+		i.Request.Query = nil
+	}
+
+	// The body length changes with scrubbing
+	i.Response.Header.Del("Content-Length")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+
+	if err := r.cassette.Save(r.Path); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// NoMatchError will be returned by the Replayer when no recorded interaction matches the request.
+type NoMatchError struct {
+	Method string
+	URL    string
+}
+
+func (e *NoMatchError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("cassette: no recorded interaction for %s %s", e.Method, e.URL)
+}
+
+// Replayer is a http.RoundTripper that answers requests with the interactions of a cassette.
+// Matching interactions are replayed in recorded order, the last match is repeated once all
+// were used.
+type Replayer struct {
+	cassette *Cassette
+
+	mu   sync.Mutex
+	used map[*Interaction]bool
+}
+
+// NewReplayer returns a replayer for the given cassette file.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCassetteReplayer(c), nil
+}
+
+// NewCassetteReplayer returns a replayer for the given cassette.
+func NewCassetteReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+		used:     make(map[*Interaction]bool),
+	}
+}
+
+// RoundTrip returns the recorded response for the request, ignoring the "Authorization" header.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *Interaction
+
+	for _, i := range r.cassette.Interactions {
+		if !i.Request.matches(req) {
+			continue
+		}
+
+		last = i
+
+		if !r.used[i] {
+			r.used[i] = true
+			return i.Response.httpResponse(req), nil
+		}
+	}
+
+	if last == nil {
+		return nil, &NoMatchError{Method: req.Method, URL: req.URL.String()}
+	}
+
+	return last.Response.httpResponse(req), nil
+}
+
+func (r *Response) httpResponse(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,130 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/adrianrudnik/anydesk/anydesktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cassettes", "test.json")
+
+	// Record against the stub server
+	server := anydesktest.NewServer("TEST_LICENSE", "TEST_PASSWORD")
+	server.Clients = []anydesk.ClientNode{{ClientID: 100, Alias: "alpha@ad", Online: true}}
+
+	api := server.API()
+	api.HTTPClient.Transport = NewRecorder(path, api.HTTPClient.Transport)
+
+	recorded, err := anydesk.NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+
+	list := anydesk.NewClientListRequest(&anydesk.ClientListSearch{Online: true})
+	list.Limit = 10
+	_, err = list.Do(api)
+	assert.NoError(t, err)
+
+	server.Close()
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	a := assert.New(t)
+	a.NotContains(string(data), "TEST_PASSWORD")
+	a.NotContains(string(data), "Authorization")
+	a.Contains(string(data), Redacted)
+
+	// Replay with different credentials and without a server
+	replayer, err := NewReplayer(path)
+	a.NoError(err)
+
+	api = anydesk.NewAPI("OTHER", "OTHER")
+	api.APIEndpoint = "http://127.0.0.1:1"
+	api.HTTPClient = &http.Client{Transport: replayer}
+
+	replayed, err := anydesk.NewSysinfoRequest().Do(api)
+	a.NoError(err)
+	a.Equal(recorded.License.ID, replayed.License.ID)
+	a.Equal(Redacted, replayed.License.APIPassword)
+
+	list = anydesk.NewClientListRequest(&anydesk.ClientListSearch{Online: true})
+	list.Limit = 10
+	resp, err := list.Do(api)
+	a.NoError(err)
+	a.Len(resp.List, 1)
+	a.Equal("alpha@ad", resp.List[0].Alias)
+
+	// Different query must not match
+	list = anydesk.NewClientListRequest(nil)
+	_, err = list.Do(api)
+	a.Error(err)
+	a.Contains(err.Error(), "no recorded interaction")
+}
+
+func TestReplayer_Order(t *testing.T) {
+	c := &Cassette{
+		Interactions: []*Interaction{
+			{Request: Request{Method: "GET", Resource: "/auth"}, Response: Response{StatusCode: 200, Body: `{"result":"first"}`}},
+			{Request: Request{Method: "GET", Resource: "/auth"}, Response: Response{StatusCode: 200, Body: `{"result":"second"}`}},
+		},
+	}
+
+	api := anydesk.NewAPI("", "")
+	api.HTTPClient = &http.Client{Transport: NewCassetteReplayer(c)}
+
+	var results []string
+	for i := 0; i < 3; i++ {
+		resp, err := anydesk.NewAuthenticationRequest().Do(api)
+		assert.NoError(t, err)
+		results = append(results, resp.Result)
+	}
+
+	assert.Equal(t, []string{"first", "second", "second"}, results)
+}
+
+// roundTripFunc adapts a function to a http.RoundTripper.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecorder_KeepsRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var sent *http.Request
+	var sentBody []byte
+
+	recorder := NewRecorder(filepath.Join(dir, "patch.json"), roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		sentBody, _ = ioutil.ReadAll(req.Body)
+
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+	}))
+
+	body := ioutil.NopCloser(strings.NewReader(`{"comment":"test"}`))
+
+	req, err := http.NewRequest("PATCH", "http://anydesk.invalid/sessions/1", body)
+	assert.NoError(t, err)
+
+	_, err = recorder.RoundTrip(req)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.True(req.Body == body)
+	a.False(sent == req)
+	a.Equal(`{"comment":"test"}`, string(sentBody))
+}
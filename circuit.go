@@ -0,0 +1,234 @@
+package anydesk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures that opens a circuit breaker.
+	DefaultFailureThreshold = 5
+
+	// DefaultCoolDown is the time an open circuit breaker rejects requests before trying again.
+	DefaultCoolDown = 30 * time.Second
+)
+
+// ErrCircuitOpen matches the errors returned while the circuit breaker rejects requests:
+//
+//   if errors.Is(err, anydesk.ErrCircuitOpen) { ... }
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// APICircuitOpenError will be returned by API requests rejected by an open circuit breaker.
+type APICircuitOpenError struct {
+	// Time at which the circuit breaker allows a trial request again.
+	RetryAt time.Time
+}
+
+func (e *APICircuitOpenError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%s, retry at %s", ErrCircuitOpen, e.RetryAt.Format(time.RFC3339))
+}
+
+// Is reports whether the target is ErrCircuitOpen.
+func (e *APICircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed passes all requests.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects all requests until the cool-down passed.
+	CircuitOpen
+
+	// CircuitHalfOpen passes a limited number of trial requests, which decide about closing or re-opening.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker stops sending requests to an unavailable API. Connection errors and 5xx responses
+// count as failures, a whole API.Do call including its endpoint failover attempts counts once.
+// After FailureThreshold consecutive failures the circuit opens and requests fail fast with
+// *APICircuitOpenError until the CoolDown passed. Then trial requests decide whether it closes again.
+type CircuitBreaker struct {
+	// Consecutive failures that open the circuit, defaults to DefaultFailureThreshold.
+	FailureThreshold int
+
+	// Time the open circuit rejects requests, defaults to DefaultCoolDown.
+	CoolDown time.Duration
+
+	// Concurrent trial requests passed while half-open, defaults to 1.
+	HalfOpenRequests int
+
+	// Source of the current time for State, defaults to the Clock of the API using the breaker
+	// or SystemClock, until it was used by an API.
+	Clock Clock
+
+	// Optional callback for state changes, i.e. for alerting. Called synchronously by the request causing the change.
+	OnStateChange func(from CircuitState, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+	apiClock Clock
+}
+
+// NewCircuitBreaker returns a circuit breaker that opens after the given consecutive failures
+// and tries again after the cool-down.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		CoolDown:         coolDown,
+	}
+}
+
+// State returns the current state of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	clock := cb.Clock
+	if clock == nil {
+		clock = clockOrSystem(cb.apiClock)
+	}
+
+	if cb.state == CircuitOpen && !clock.Now().Before(cb.openedAt.Add(cb.coolDown())) {
+		return CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// useClock sets the clock of the API using the breaker, which is the default for State.
+func (cb *CircuitBreaker) useClock(clock Clock) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.apiClock = clock
+}
+
+// allow reports whether a request may be sent now.
+func (cb *CircuitBreaker) allow(now time.Time) error {
+	cb.mu.Lock()
+	from := cb.state
+
+	if cb.state == CircuitOpen {
+		retryAt := cb.openedAt.Add(cb.coolDown())
+		if now.Before(retryAt) {
+			cb.mu.Unlock()
+			return &APICircuitOpenError{RetryAt: retryAt}
+		}
+
+		cb.state = CircuitHalfOpen
+		cb.trials = 0
+	}
+
+	if cb.state == CircuitHalfOpen {
+		if cb.trials >= cb.halfOpenRequests() {
+			cb.mu.Unlock()
+			return &APICircuitOpenError{RetryAt: now.Add(cb.coolDown())}
+		}
+
+		cb.trials++
+	}
+
+	to := cb.state
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+
+	return nil
+}
+
+// record adds the outcome of an allowed request.
+func (cb *CircuitBreaker) record(success bool, now time.Time) {
+	cb.mu.Lock()
+	from := cb.state
+
+	switch {
+	case success:
+		cb.failures = 0
+		cb.state = CircuitClosed
+	case cb.state == CircuitHalfOpen:
+		cb.state = CircuitOpen
+		cb.openedAt = now
+	default:
+		cb.failures++
+
+		if cb.failures >= cb.failureThreshold() {
+			cb.state = CircuitOpen
+			cb.openedAt = now
+		}
+	}
+
+	if cb.state != CircuitHalfOpen {
+		cb.trials = 0
+	}
+
+	to := cb.state
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+}
+
+// release returns the trial of an allowed request that was not sent, without recording an outcome.
+func (cb *CircuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitHalfOpen && cb.trials > 0 {
+		cb.trials--
+	}
+}
+
+func (cb *CircuitBreaker) notify(from CircuitState, to CircuitState) {
+	if from != to && cb.OnStateChange != nil {
+		cb.OnStateChange(from, to)
+	}
+}
+
+func (cb *CircuitBreaker) failureThreshold() int {
+	if cb.FailureThreshold <= 0 {
+		return DefaultFailureThreshold
+	}
+
+	return cb.FailureThreshold
+}
+
+func (cb *CircuitBreaker) coolDown() time.Duration {
+	if cb.CoolDown <= 0 {
+		return DefaultCoolDown
+	}
+
+	return cb.CoolDown
+}
+
+func (cb *CircuitBreaker) halfOpenRequests() int {
+	if cb.HalfOpenRequests <= 0 {
+		return 1
+	}
+
+	return cb.HalfOpenRequests
+}
@@ -0,0 +1,133 @@
+package anydesk
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var changes []string
+
+	cb := NewCircuitBreaker(2, time.Minute)
+	cb.OnStateChange = func(from CircuitState, to CircuitState) {
+		changes = append(changes, from.String()+">"+to.String())
+	}
+
+	now := time.Unix(1590504000, 0)
+
+	assert.NoError(t, cb.allow(now))
+	cb.record(false, now)
+	assert.Equal(t, CircuitClosed, cb.state)
+
+	// a success resets the consecutive failures
+	assert.NoError(t, cb.allow(now))
+	cb.record(true, now)
+	assert.NoError(t, cb.allow(now))
+	cb.record(false, now)
+	assert.Equal(t, CircuitClosed, cb.state)
+
+	assert.NoError(t, cb.allow(now))
+	cb.record(false, now)
+	assert.Equal(t, CircuitOpen, cb.state)
+
+	err := cb.allow(now.Add(30 * time.Second))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	var openErr *APICircuitOpenError
+	if assert.True(t, errors.As(err, &openErr)) {
+		assert.Equal(t, now.Add(time.Minute), openErr.RetryAt)
+	}
+
+	// a single trial request is allowed after the cool-down
+	later := now.Add(time.Minute)
+	assert.NoError(t, cb.allow(later))
+	assert.Equal(t, CircuitHalfOpen, cb.state)
+	assert.True(t, errors.Is(cb.allow(later), ErrCircuitOpen))
+
+	// a failed trial opens the circuit again
+	cb.record(false, later)
+	assert.Equal(t, CircuitOpen, cb.state)
+	assert.True(t, errors.Is(cb.allow(later.Add(time.Second)), ErrCircuitOpen))
+
+	// a successful trial closes it
+	later = later.Add(time.Minute)
+	assert.NoError(t, cb.allow(later))
+	cb.record(true, later)
+	assert.Equal(t, CircuitClosed, cb.state)
+
+	assert.Equal(t, []string{
+		"closed>open",
+		"open>half-open",
+		"half-open>open",
+		"open>half-open",
+		"half-open>closed",
+	}, changes)
+}
+
+func TestCircuitBreaker_Defaults(t *testing.T) {
+	cb := &CircuitBreaker{}
+
+	assert.Equal(t, DefaultFailureThreshold, cb.failureThreshold())
+	assert.Equal(t, DefaultCoolDown, cb.coolDown())
+	assert.Equal(t, 1, cb.halfOpenRequests())
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.Equal(t, "CircuitState(7)", CircuitState(7).String())
+}
+
+func TestAPI_Do_CircuitBreaker(t *testing.T) {
+	var status, calls int32 = 503, 0
+
+	primary := newFailoverTestServer(t, &status, &calls)
+	defer primary.Close()
+
+	secondary := newFailoverTestServer(t, &status, &calls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+	api.ProbeInterval = time.Hour
+	api.CircuitBreaker = NewCircuitBreaker(2, time.Hour)
+
+	// failover attempts of a single request count as one failure
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.EqualError(t, err, "503 Service Unavailable")
+	assert.Equal(t, CircuitClosed, api.CircuitBreaker.State())
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpen, api.CircuitBreaker.State())
+	assert.Equal(t, int32(4), calls)
+
+	// requests fail fast without reaching the API
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(4), calls)
+}
+
+func TestAPI_Do_CircuitBreakerClientError(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.APIEndpoint = server.URL
+	api.CircuitBreaker = NewCircuitBreaker(1, time.Hour)
+
+	// 4xx responses prove the API is available
+	for i := 0; i < 3; i++ {
+		_, err := NewAuthenticationRequest().Do(api)
+		assert.IsType(t, &APINotFoundError{}, err)
+	}
+
+	assert.Equal(t, int32(3), calls)
+	assert.Equal(t, CircuitClosed, api.CircuitBreaker.State())
+}
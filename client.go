@@ -4,46 +4,144 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strconv"
 	"time"
 )
 
+// Sortable properties of client lists, see PaginationOptions.Sort.
+const (
+	ClientSortID         = "cid"
+	ClientSortVersion    = "client-version"
+	ClientSortAlias      = "alias"
+	ClientSortOnline     = "online"
+	ClientSortOnlineTime = "online-time"
+	ClientSortComment    = "comment"
+)
+
 // ClientNode is the common structure of the API for AnyDesk clients.
 type ClientNode struct {
 	// ID of the client the response is about.
-	ClientID int64 `json:"cid"`
+	ClientID int64 `json:"cid" schema:"required"`
 
 	// Current version of the clients AnyDesk software.
-	ClientVersion string `json:"client-version"`
+	ClientVersion string `json:"client-version" schema:"required"`
 
 	// Currently set alias of the client.
-	Alias string `json:"alias"`
+	// Empty if the client has no alias, see HasAlias.
+	Alias string `json:"alias" schema:"required"`
 
 	// Indicates if the client is currently online.
-	Online bool `json:"online"`
+	Online bool `json:"online" schema:"required"`
 
 	// Comment for the given client, as defined in the address book.
-	Comment string `json:"comment"`
+	// Empty if the client has no comment, see HasComment.
+	Comment string `json:"comment" schema:"required"`
 
 	// Seconds since the client came online.
 	// Will be -1 if client is Offline, but please use the .Online attribute for check.
-	OnlineSinceSeconds int64 `json:"online-time"`
+	OnlineSinceSeconds int64 `json:"online-time" schema:"required"`
 
 	// Last five sessions that this client was involved in.
 	// Only available if queried by ClientDetailRequest.
 	LastSessions []SessionNode `json:"last-sessions"`
+
+	// User the client is assigned to, nil if none.
+	UserRef *UserRef `json:"user_ref"`
+
+	// Fields returned by the API that are not mapped by this struct.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// Time the online-time was measured, zero for nodes not fetched from the API.
+	fetchedAt time.Time
+
+	// Alias and comment were returned and not null.
+	hasAlias   bool
+	hasComment bool
+}
+
+// UnmarshalJSON decodes the node and keeps unknown fields in Extra.
+func (cn *ClientNode) UnmarshalJSON(data []byte) error {
+	type plain ClientNode
+
+	extra, err := decodeModel(data, (*plain)(cn),
+		presence{key: "alias", set: &cn.hasAlias},
+		presence{key: "comment", set: &cn.hasComment},
+	)
+	if err != nil {
+		return err
+	}
+
+	cn.Extra = extra
+
+	return nil
+}
+
+// MarshalJSON encodes the node including the unknown fields kept in Extra.
+func (cn ClientNode) MarshalJSON() ([]byte, error) {
+	type plain ClientNode
+
+	return encodeModel(plain(cn), cn.Extra)
 }
 
+// HasAlias reports whether the client has an alias. Nodes returned by the API have none if it is null.
+func (cn *ClientNode) HasAlias() bool {
+	return cn.hasAlias || cn.Alias != ""
+}
+
+// HasComment reports whether the client has a comment. Nodes returned by the API have none if it is null.
+func (cn *ClientNode) HasComment() bool {
+	return cn.hasComment || cn.Comment != ""
+}
+
+// OnlineSince returns the time when the client came online, relative to the time the node was fetched
+// according to the Clock of the API. Nodes not fetched from the API are relative to the SystemClock.
 func (cn *ClientNode) OnlineSince() time.Time {
-	n := time.Now()
-	return n.Add(time.Second * (-1 * time.Duration(cn.OnlineSinceSeconds)))
+	at := cn.fetchedAt
+	if at.IsZero() {
+		at = SystemClock.Now()
+	}
+
+	return at.Add(time.Second * (-1 * time.Duration(cn.OnlineSinceSeconds)))
 }
 
 // ClientSlimNode is the common short representation of the API.
 // It does not contain all available information and is used in list queries.
 type ClientSlimNode struct {
-	ClientID      int64  `json:"cid"`
-	ClientVersion string `json:"string"`
-	Alias         string `json:"alias"`
+	ClientID      int64  `json:"cid" schema:"required"`
+	ClientVersion string `json:"client-version,omitempty"`
+	Alias         string `json:"alias" schema:"required"`
+
+	// Fields returned by the API that are not mapped by this struct.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	hasAlias bool
+}
+
+// UnmarshalJSON decodes the node and keeps unknown fields in Extra.
+func (n *ClientSlimNode) UnmarshalJSON(data []byte) error {
+	type plain ClientSlimNode
+
+	extra, err := decodeModel(data, (*plain)(n), presence{key: "alias", set: &n.hasAlias})
+	if err != nil {
+		return err
+	}
+
+	n.Extra = extra
+
+	return nil
+}
+
+// MarshalJSON encodes the node including the unknown fields kept in Extra.
+func (n ClientSlimNode) MarshalJSON() ([]byte, error) {
+	type plain ClientSlimNode
+
+	return encodeModel(plain(n), n.Extra)
+}
+
+// HasAlias reports whether the client has an alias. Nodes returned by the API have none if it is null.
+func (n *ClientSlimNode) HasAlias() bool {
+	return n.hasAlias || n.Alias != ""
 }
 
 // ClientDetailRequest is used to read details about a single client from the REST API.
@@ -51,27 +149,51 @@ type ClientDetailRequest struct {
 	*BaseRequest
 }
 
+func (req *ClientDetailRequest) requiredCapabilities() []Capability {
+	return []Capability{CapabilityClientDetail}
+}
+
+// Validate checks the client ID of the resource.
+func (req *ClientDetailRequest) Validate() error {
+	if req.BaseRequest == nil {
+		return invalid("Resource", "must not be empty")
+	}
+
+	id := resourceID(req.Resource, "/clients/")
+	if cid, err := strconv.ParseInt(id, 10, 64); err != nil || cid <= 0 {
+		return invalid("ClientID", "%q is not a valid client ID", id)
+	}
+
+	return req.BaseRequest.Validate()
+}
+
 // Do will execute the "/auth" query against the given API.
 func (req *ClientDetailRequest) Do(api *API) (r *ClientDetailResponse, err error) {
 	r = newClientDetailResponse()
 
 	body, err := api.Do(req)
+	r.Meta = req.Meta()
+
 	if err != nil {
 		return
 	}
 
-	err = json.Unmarshal(body, r)
+	err = api.decode(req, body, r)
 	if err != nil {
 		return
 	}
 
+	if r.ClientNode != nil {
+		r.ClientNode.fetchedAt = r.Meta.FetchedAt
+	}
+
 	return
 }
 
 // NewClientDetailRequest returns a clean API request to retrieve client details from the API.
 func NewClientDetailRequest(clientID int64) *ClientDetailRequest {
 	return &ClientDetailRequest{
-		&BaseRequest{
+		BaseRequest: &BaseRequest{
 			Method:   "GET",
 			Resource: fmt.Sprintf("/clients/%d", clientID),
 		},
@@ -81,59 +203,167 @@ func NewClientDetailRequest(clientID int64) *ClientDetailRequest {
 // ClientDetailResponse contains all fields available to the client details API resource.
 type ClientDetailResponse struct {
 	*ClientNode
+
+	// Details about the http exchange.
+	Meta *ResponseMeta `json:"-"`
 }
 
-// OnlineSince returns the original time when the client came online.
+// OnlineSince returns the original time when the client came online, relative to the fetch time.
 func (r *ClientDetailResponse) OnlineSince() time.Time {
-	n := time.Now()
-	return n.Add(time.Second * (-1 * time.Duration(r.OnlineSinceSeconds)))
+	return r.ClientNode.OnlineSince()
 }
 
 func newClientDetailResponse() *ClientDetailResponse {
-	return &ClientDetailResponse{}
+	return &ClientDetailResponse{
+		ClientNode: &ClientNode{},
+	}
 }
 
 // ClientListRequest is used to read a list of clients from the API resource.
 type ClientListRequest struct {
 	*BaseRequest
 	*PaginationOptions
+
+	search *ClientListSearch
 }
 
 // ClientListSearch configures the search  params for NewClientListRequest.
+// Online clients are filtered by the API, all other conditions, including OfflineOnly, are applied
+// client-side while the list is read. Offset and Limit then apply to the matching clients.
+//
+// As the API can not page over the matching clients, every Do reads the whole list, also for a
+// single page. Walking N pages with HasMore reads the list N times, prefer the default Infinite
+// limit with Do or Each to process all matching clients.
 type ClientListSearch struct {
 	// Limits search to online clients.
 	// Setting this to false will not list only offline clients.
+	//
+	// Deprecated: Use OnlineState, which also allows to list only offline clients.
 	Online bool
+
+	// Limits search to online or offline clients.
+	OnlineState OnlineFilter
+
+	// Limits search to clients with an alias starting with the given prefix.
+	AliasPrefix string
+
+	// Limits search to clients with an alias containing the given string.
+	AliasContains string
+
+	// Limits search to clients with an alias matching the given expression.
+	AliasPattern *regexp.Regexp
+
+	// Limits search to clients with at least the given version, i.e. "6.0".
+	MinVersion string
+
+	// Limits search to clients with at most the given version, i.e. "6.3.2".
+	MaxVersion string
+
+	// Limits search to clients with a comment containing the given string.
+	CommentContains string
+
+	// Limits search to online clients that are online for at least the given duration.
+	MinOnlineTime time.Duration
+
+	// Limits search to online clients that are online for at most the given duration.
+	MaxOnlineTime time.Duration
 }
 
 // Do will execute the request against the API.
 func (req *ClientListRequest) Do(api *API) (r *ClientListResponse, err error) {
+	if req.search.filtered() {
+		return req.doFiltered(api)
+	}
+
 	r = newClientListResponse()
 
 	body, err := api.DoPaginated(req)
+	r.Meta = req.Meta()
+
 	if err != nil {
 		return
 	}
 
-	err = json.Unmarshal(body, r)
+	err = api.decode(req, body, r)
 	if err != nil {
 		return
 	}
 
+	for i := range r.List {
+		r.List[i].fetchedAt = r.Meta.FetchedAt
+	}
+
 	return
 }
 
+func (req *ClientListRequest) requiredCapabilities() []Capability {
+	if req.search != nil && req.search.onlineFilter() == OnlineOnly {
+		return []Capability{CapabilityClientList, CapabilityClientOnlineFilter}
+	}
+
+	return []Capability{CapabilityClientList}
+}
+
+// Validate checks the search and pagination settings.
+func (req *ClientListRequest) Validate() error {
+	if err := req.search.validate(); err != nil {
+		return err
+	}
+
+	err := req.PaginationOptions.validate(
+		ClientSortID,
+		ClientSortVersion,
+		ClientSortAlias,
+		ClientSortOnline,
+		ClientSortOnlineTime,
+		ClientSortComment,
+	)
+	if err != nil {
+		return err
+	}
+
+	return req.BaseRequest.Validate()
+}
+
+// Each executes the request and calls fn for every client while the response is decoded,
+// without holding the whole list in memory. An error returned by fn stops the iteration.
+// Client-side conditions of the search are applied to the requested page, clients not matching
+// them are skipped.
+func (req *ClientListRequest) Each(api *API, fn func(node *ClientNode) error) (*PaginatedResult, error) {
+	return api.DoStream(req, func(dec *json.Decoder) error {
+		node := &ClientNode{}
+		if err := dec.Decode(node); err != nil {
+			return err
+		}
+
+		if !req.search.Match(node) {
+			return nil
+		}
+
+		// The response meta is only available once the whole list was decoded
+		node.fetchedAt = api.now()
+
+		return fn(node)
+	})
+}
+
 // NewClientListRequest returns a clean API request to retrieve a list of clients from the API.
 func NewClientListRequest(search *ClientListSearch) *ClientListRequest {
 	var q *url.Values
 
 	if search != nil {
+		// Keep a copy for the client-side conditions, matching the query
+		s := *search
+		search = &s
+
 		q = &url.Values{}
 
-		if search.Online {
+		// The API can not list only offline clients, these are filtered client-side
+		if search.onlineFilter() == OnlineOnly {
 			q.Set("online", "true")
 		}
 	}
+
 	return &ClientListRequest{
 		BaseRequest: &BaseRequest{
 			Method:   "GET",
@@ -141,14 +371,19 @@ func NewClientListRequest(search *ClientListSearch) *ClientListRequest {
 			Query:    q,
 		},
 		PaginationOptions: NewPaginationOptions(),
+		search:            search,
 	}
 }
 
 // ClientListResponse contains all fields available for client lists from the API resource.
 type ClientListResponse struct {
 	*PaginatedResult
+
+	// Details about the http exchange.
+	Meta *ResponseMeta `json:"-"`
+
 	Online bool         `json:"online"`
-	List   []ClientNode `json:"list"`
+	List   []ClientNode `json:"list" schema:"required"`
 }
 
 func newClientListResponse() *ClientListResponse {
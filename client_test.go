@@ -25,7 +25,7 @@ func TestNewClientDetailRequest(t *testing.T) {
 	a.Equal("TEST-COMMENTA", resp.Comment)
 	a.True(resp.Online)
 	a.Equal(int64(456), resp.OnlineSinceSeconds)
-	a.Equal(time.Now().Unix(), resp.OnlineSince().Add(time.Second*time.Duration(resp.OnlineSinceSeconds)).Unix())
+	a.Equal(resp.Meta.FetchedAt, resp.OnlineSince().Add(time.Second*time.Duration(resp.OnlineSinceSeconds)))
 
 	a.Len(resp.LastSessions, 2)
 
@@ -67,7 +67,7 @@ func ExampleNewClientDetailRequest() {
 	response, _ := request.Do(api)
 
 	fmt.Printf(
-		"ID: %s, Alias: %s, Version: %s",
+		"ID: %d, Alias: %s, Version: %s",
 		response.ClientID,
 		response.Alias,
 		response.ClientVersion,
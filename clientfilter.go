@@ -0,0 +1,215 @@
+package anydesk
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OnlineFilter limits client lists by the online state of the clients.
+type OnlineFilter int
+
+const (
+	// AnyOnlineState lists online and offline clients.
+	AnyOnlineState OnlineFilter = iota
+
+	// OnlineOnly lists online clients only.
+	OnlineOnly
+
+	// OfflineOnly lists offline clients only.
+	OfflineOnly
+)
+
+// filtered reports whether the search contains conditions the API does not support,
+// which are applied client-side. The API can only limit lists to online clients.
+func (s *ClientListSearch) filtered() bool {
+	return s != nil && (s.onlineFilter() == OfflineOnly ||
+		s.AliasPrefix != "" ||
+		s.AliasContains != "" ||
+		s.AliasPattern != nil ||
+		s.MinVersion != "" ||
+		s.MaxVersion != "" ||
+		s.CommentContains != "" ||
+		s.MinOnlineTime > 0 ||
+		s.MaxOnlineTime > 0)
+}
+
+// onlineFilter returns the effective online filter, honoring the legacy Online flag.
+func (s *ClientListSearch) onlineFilter() OnlineFilter {
+	if s.OnlineState == AnyOnlineState && s.Online {
+		return OnlineOnly
+	}
+
+	return s.OnlineState
+}
+
+// Match reports whether the client matches all conditions of the search.
+// A nil search matches every client.
+func (s *ClientListSearch) Match(node *ClientNode) bool {
+	if s == nil {
+		return true
+	}
+
+	switch s.onlineFilter() {
+	case OnlineOnly:
+		if !node.Online {
+			return false
+		}
+	case OfflineOnly:
+		if node.Online {
+			return false
+		}
+	}
+
+	if !strings.HasPrefix(node.Alias, s.AliasPrefix) ||
+		!strings.Contains(node.Alias, s.AliasContains) ||
+		!strings.Contains(node.Comment, s.CommentContains) {
+		return false
+	}
+
+	if s.AliasPattern != nil && !s.AliasPattern.MatchString(node.Alias) {
+		return false
+	}
+
+	if s.MinVersion != "" && compareVersions(node.ClientVersion, s.MinVersion) < 0 {
+		return false
+	}
+
+	if s.MaxVersion != "" && compareVersions(node.ClientVersion, s.MaxVersion) > 0 {
+		return false
+	}
+
+	if s.MinOnlineTime > 0 || s.MaxOnlineTime > 0 {
+		online := time.Duration(node.OnlineSinceSeconds) * time.Second
+
+		if !node.Online || online < s.MinOnlineTime || (s.MaxOnlineTime > 0 && online > s.MaxOnlineTime) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validate checks the search parameters, a nil search is valid.
+func (s *ClientListSearch) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.OnlineState < AnyOnlineState || s.OnlineState > OfflineOnly {
+		return invalid("OnlineState", "unknown online filter %d", s.OnlineState)
+	}
+
+	if s.MinVersion != "" && !versionPattern.MatchString(s.MinVersion) {
+		return invalid("MinVersion", "%q is not a version", s.MinVersion)
+	}
+
+	if s.MaxVersion != "" && !versionPattern.MatchString(s.MaxVersion) {
+		return invalid("MaxVersion", "%q is not a version", s.MaxVersion)
+	}
+
+	if s.MinOnlineTime < 0 {
+		return invalid("MinOnlineTime", "%s is negative", s.MinOnlineTime)
+	}
+
+	if s.MaxOnlineTime < 0 || (s.MaxOnlineTime > 0 && s.MaxOnlineTime < s.MinOnlineTime) {
+		return invalid("MaxOnlineTime", "%s is below MinOnlineTime", s.MaxOnlineTime)
+	}
+
+	return nil
+}
+
+var versionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// compareVersions compares dotted version numbers, i.e. "6.2.3", returning -1, 0 or 1.
+// Missing parts count as zero, non-numeric parts are compared as strings.
+func compareVersions(a string, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		va, vb := "0", "0"
+
+		if i < len(pa) {
+			va = pa[i]
+		}
+
+		if i < len(pb) {
+			vb = pb[i]
+		}
+
+		na, errA := strconv.ParseInt(va, 10, 64)
+		nb, errB := strconv.ParseInt(vb, 10, 64)
+
+		switch {
+		case errA == nil && errB == nil && na < nb:
+			return -1
+		case errA == nil && errB == nil && na > nb:
+			return 1
+		case (errA != nil || errB != nil) && va != vb:
+			if va < vb {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// doFiltered executes a list request with client-side conditions. The whole list is streamed in the
+// requested order, the pagination is applied to the matching clients. Every page therefore reads the
+// whole list, see ClientListSearch.
+func (req *ClientListRequest) doFiltered(api *API) (r *ClientListResponse, err error) {
+	r = newClientListResponse()
+
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	page := req.PaginationOptions
+	req.PaginationOptions = &PaginationOptions{
+		Offset: 0,
+		Limit:  Infinite,
+		Sort:   page.Sort,
+		Order:  page.Order,
+	}
+
+	defer func() {
+		req.PaginationOptions = page
+	}()
+
+	var matched int64
+
+	// Each only passes matching clients
+	_, err = req.Each(api, func(node *ClientNode) error {
+		if matched >= page.Offset && (page.Limit == Infinite || matched < page.Offset+page.Limit) {
+			r.List = append(r.List, *node)
+		}
+
+		matched++
+
+		return nil
+	})
+	r.Meta = req.Meta()
+
+	if err != nil {
+		return
+	}
+
+	r.Online = req.search.onlineFilter() == OnlineOnly
+	r.PaginatedResult = &PaginatedResult{
+		Count:    matched,
+		Selected: int64(len(r.List)),
+		Offset:   page.Offset,
+		Limit:    page.Limit,
+	}
+
+	if r.Selected == 0 {
+		err = &APINoResultsError{}
+	}
+
+	return
+}
@@ -0,0 +1,157 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("5.4.0", "5.4"))
+	assert.Equal(t, -1, compareVersions("5.4.0", "5.10"))
+	assert.Equal(t, 1, compareVersions("6", "5.99.1"))
+	assert.Equal(t, -1, compareVersions("5.4.0-beta", "5.4.0-rc"))
+}
+
+func TestClientListSearch_Match(t *testing.T) {
+	node := &ClientNode{
+		Alias:              "BER-42@ad",
+		ClientVersion:      "6.2.3",
+		Comment:            "reception desk",
+		Online:             true,
+		OnlineSinceSeconds: 3600,
+	}
+
+	tests := []struct {
+		search *ClientListSearch
+		match  bool
+	}{
+		{nil, true},
+		{&ClientListSearch{}, true},
+		{&ClientListSearch{Online: true}, true},
+		{&ClientListSearch{OnlineState: OfflineOnly}, false},
+		{&ClientListSearch{AliasPrefix: "BER-"}, true},
+		{&ClientListSearch{AliasPrefix: "MUC-"}, false},
+		{&ClientListSearch{AliasContains: "42"}, true},
+		{&ClientListSearch{AliasPattern: regexp.MustCompile(`(?i)^ber-\d+`)}, true},
+		{&ClientListSearch{AliasPattern: regexp.MustCompile(`^MUC`)}, false},
+		{&ClientListSearch{MinVersion: "6.2", MaxVersion: "6.2.3"}, true},
+		{&ClientListSearch{MinVersion: "6.3"}, false},
+		{&ClientListSearch{MaxVersion: "6.2.2"}, false},
+		{&ClientListSearch{CommentContains: "desk"}, true},
+		{&ClientListSearch{CommentContains: "lab"}, false},
+		{&ClientListSearch{MinOnlineTime: time.Hour}, true},
+		{&ClientListSearch{MinOnlineTime: 2 * time.Hour}, false},
+		{&ClientListSearch{MaxOnlineTime: time.Minute}, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, tt.search.Match(node), "%+v", tt.search)
+	}
+
+	offline := &ClientNode{Online: false, OnlineSinceSeconds: -1}
+	assert.True(t, (&ClientListSearch{OnlineState: OfflineOnly}).Match(offline))
+	assert.False(t, (&ClientListSearch{MaxOnlineTime: time.Hour}).Match(offline))
+}
+
+func TestClientListSearch_Validate(t *testing.T) {
+	assert.NoError(t, NewClientListRequest(&ClientListSearch{MinVersion: "5.4", MaxVersion: "6"}).Validate())
+
+	tests := map[string]*ClientListSearch{
+		"OnlineState":   {OnlineState: 3},
+		"MinVersion":    {MinVersion: "latest"},
+		"MaxVersion":    {MaxVersion: "6.x"},
+		"MinOnlineTime": {MinOnlineTime: -time.Second},
+		"MaxOnlineTime": {MinOnlineTime: time.Hour, MaxOnlineTime: time.Minute},
+	}
+
+	for field, search := range tests {
+		assert.Equal(t, field, NewClientListRequest(search).Validate().(*ValidationError).Field)
+	}
+}
+
+func TestNewClientListRequest_OnlineState(t *testing.T) {
+	assert.Equal(t, "true", NewClientListRequest(&ClientListSearch{Online: true}).Query.Get("online"))
+	assert.Equal(t, "true", NewClientListRequest(&ClientListSearch{OnlineState: OnlineOnly}).Query.Get("online"))
+	assert.Equal(t, "", NewClientListRequest(&ClientListSearch{OnlineState: OfflineOnly}).Query.Get("online"))
+	assert.Equal(t, "", NewClientListRequest(&ClientListSearch{}).Query.Get("online"))
+}
+
+func TestClientListRequest_DoOfflineOnly(t *testing.T) {
+	// the fixture server ignores the query, like the API for offline clients
+	server := newFixtureServer(t, "./_tests/client_list_all.json")
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	resp, err := NewClientListRequest(&ClientListSearch{OnlineState: OfflineOnly}).Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), resp.Count)
+	assert.Len(t, resp.List, 6)
+
+	for _, node := range resp.List {
+		assert.False(t, node.Online, "client %d", node.ClientID)
+	}
+}
+
+func TestClientListRequest_DoFiltered(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/client_list_all.json")
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	req := NewClientListRequest(&ClientListSearch{OnlineState: OfflineOnly, MinVersion: "5.4"})
+	req.Offset = 1
+	req.Limit = 2
+
+	resp, err := req.Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), resp.Count)
+	assert.Equal(t, int64(2), resp.Selected)
+	assert.Equal(t, int64(1), resp.Offset)
+	assert.Equal(t, []int64{125, 126}, []int64{resp.List[0].ClientID, resp.List[1].ClientID})
+	assert.Equal(t, 200, resp.Meta.StatusCode)
+
+	// the pagination of the request is untouched
+	assert.Equal(t, int64(1), req.Offset)
+
+	next, hasMore := resp.HasMore(req)
+	assert.True(t, hasMore)
+	assert.Equal(t, int64(3), next.Offset)
+
+	_, err = NewClientListRequest(&ClientListSearch{AliasPrefix: "BER-"}).Do(api)
+	assert.IsType(t, &APINoResultsError{}, err)
+}
+
+func TestClientListRequest_DoRepeated(t *testing.T) {
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		queries = append(queries, req.URL.RawQuery)
+
+		data, err := ioutil.ReadFile("./_tests/client_list_all.json")
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	req := NewClientListRequest(&ClientListSearch{OnlineState: OnlineOnly, MinVersion: "5.4"})
+
+	for i := 0; i < 2; i++ {
+		_, err := req.Do(api)
+		assert.NoError(t, err)
+		assert.Equal(t, "/clients", req.Resource)
+	}
+
+	if assert.Len(t, queries, 2) {
+		assert.Equal(t, "limit=-1&offset=0&online=true&order=desc", queries[0])
+		assert.Equal(t, queries[0], queries[1])
+	}
+}
@@ -0,0 +1,47 @@
+package anydesk
+
+import (
+	"time"
+)
+
+// Clock provides the current time and waiting, so time dependent behaviour can be tested
+// deterministically, i.e. with anydesktest.FakeClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep pauses for the given duration.
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the Clock based on the system time, used when no other clock is configured.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// clockOrSystem returns the given clock or, if nil, the SystemClock.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+
+	return c
+}
+
+// clock returns the configured Clock or, if nil, the SystemClock.
+func (api *API) clock() Clock {
+	return clockOrSystem(api.Clock)
+}
+
+// now returns the current time of the configured Clock.
+func (api *API) now() time.Time {
+	return api.clock().Now()
+}
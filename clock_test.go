@@ -0,0 +1,49 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a Clock for the tests of this package, which can not use anydesktest.FakeClock.
+// Sleep advances the time instead of waiting.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1445440997, 0)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) Sleep(d time.Duration) {
+	c.Add(d)
+}
+
+// Add advances the time by d.
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestAPI_Clock(t *testing.T) {
+	api := NewAPI("", "")
+	assert.Equal(t, SystemClock, api.clock())
+
+	clock := newTestClock()
+	api.Clock = clock
+	clock.Sleep(time.Minute)
+
+	assert.Equal(t, time.Unix(1445440997+60, 0), api.now())
+}
@@ -0,0 +1,55 @@
+// Command anydesk-mock serves a fake AnyDesk API from a directory of JSON fixture files.
+//
+// Clients and sessions are read from files in the same shape as the AnyDesk API responses,
+// i.e. "_tests/client_list_all.json" and "_tests/client_detail.json":
+//
+//   anydesk-mock -dir ./_tests -addr 127.0.0.1:8081
+//
+// Without -license and -password any credentials are accepted. Session comment changes are
+// kept in memory, unless -write is given, which writes them back into the fixture files.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/adrianrudnik/anydesk/anydesktest"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory containing the JSON fixture files")
+	addr := flag.String("addr", "127.0.0.1:8081", "address to listen on")
+	license := flag.String("license", "", "license ID to accept, empty accepts any credentials")
+	password := flag.String("password", os.Getenv("ANYDESK_API_PASSWORD"), "API password to verify request signatures with")
+	write := flag.Bool("write", false, "write changes back into the fixture files")
+	flag.Parse()
+
+	fixtures, err := anydesktest.LoadFixtures(*dir)
+	if err != nil {
+		log.Fatalf("could not load fixtures: %s", err)
+	}
+
+	server := anydesktest.NewHandler(*license, *password)
+	server.AcceptAnyCredentials = *license == ""
+	fixtures.Apply(server)
+
+	if *write {
+		server.OnChange = func() {
+			if err := fixtures.Save(server); err != nil {
+				log.Printf("could not write fixtures: %s", err)
+			}
+		}
+	}
+
+	log.Printf(
+		"serving %d clients and %d sessions from %s on http://%s",
+		len(fixtures.Clients),
+		len(fixtures.Sessions),
+		fixtures.Dir,
+		*addr,
+	)
+
+	log.Fatal((&http.Server{Addr: *addr, Handler: server}).ListenAndServe())
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/adrianrudnik/anydesk/format"
+)
+
+func runClientsList(c *cli, args []string) error {
+	fs := flag.NewFlagSet("clients list", flag.ContinueOnError)
+	online := fs.Bool("online", false, "only list online clients")
+	offline := fs.Bool("offline", false, "only list offline clients")
+	aliasPrefix := fs.String("alias-prefix", "", "only list clients with an alias starting with the prefix")
+	minVersion := fs.String("min-version", "", "only list clients with at least the given version")
+	pagination := paginationFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	search := &anydesk.ClientListSearch{
+		AliasPrefix: *aliasPrefix,
+		MinVersion:  *minVersion,
+	}
+
+	switch {
+	case *online && *offline:
+		return errors.New("-online and -offline are mutually exclusive")
+	case *online:
+		search.OnlineState = anydesk.OnlineOnly
+	case *offline:
+		search.OnlineState = anydesk.OfflineOnly
+	}
+
+	req := anydesk.NewClientListRequest(search)
+	req.PaginationOptions = pagination
+
+	resp, err := req.Do(c.api)
+	if err != nil {
+		var noResults *anydesk.APINoResultsError
+		if errors.As(err, &noResults) {
+			return c.renderer.Clients(c.out, nil)
+		}
+
+		return err
+	}
+
+	return c.renderer.Clients(c.out, resp.List)
+}
+
+func runClientsShow(c *cli, args []string) error {
+	fs := flag.NewFlagSet("clients show", flag.ContinueOnError)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: clients show <cid>")
+	}
+
+	cid, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid client ID %q", fs.Arg(0))
+	}
+
+	resp, err := anydesk.NewClientDetailRequest(cid).Do(c.api)
+	if err != nil {
+		return err
+	}
+
+	if err := c.renderer.Clients(c.out, []anydesk.ClientNode{*resp.ClientNode}); err != nil {
+		return err
+	}
+
+	// Tables are meant for humans, so the last sessions are shown as well
+	if c.renderer.Format != format.Table || len(resp.LastSessions) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(c.out, "\nLast sessions:")
+
+	sessions := format.NewRenderer(format.Table, nil)
+	return sessions.Sessions(c.out, resp.LastSessions)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// paginationFlags registers the pagination flags and returns the options they are written to.
+func paginationFlags(fs *flag.FlagSet) *anydesk.PaginationOptions {
+	p := anydesk.NewPaginationOptions()
+
+	fs.Int64Var(&p.Offset, "offset", p.Offset, "result offset")
+	fs.Int64Var(&p.Limit, "limit", p.Limit, "result limit, -1 for unlimited results")
+	fs.StringVar(&p.Sort, "sort", p.Sort, "sort by property name")
+	fs.Var((*orderFlag)(&p.Order), "order", "sort order, asc or desc")
+
+	return p
+}
+
+// orderFlag parses the sort order.
+type orderFlag anydesk.SortOrder
+
+func (f *orderFlag) String() string {
+	return string(*f)
+}
+
+func (f *orderFlag) Set(v string) error {
+	switch o := anydesk.SortOrder(strings.ToLower(v)); o {
+	case anydesk.OrderAsc, anydesk.OrderDesc:
+		*f = orderFlag(o)
+		return nil
+	default:
+		return fmt.Errorf("invalid order %q, use asc or desc", v)
+	}
+}
+
+// directionFlag parses the session direction.
+type directionFlag anydesk.SessionDirection
+
+func (f *directionFlag) String() string {
+	return string(*f)
+}
+
+func (f *directionFlag) Set(v string) error {
+	switch d := anydesk.SessionDirection(strings.ToLower(v)); d {
+	case anydesk.DirectionIn, anydesk.DirectionOut, anydesk.DirectionInOut:
+		*f = directionFlag(d)
+		return nil
+	default:
+		return fmt.Errorf("invalid direction %q, use in, out or inout", v)
+	}
+}
+
+// timeFlag parses absolute points in time, either RFC 3339, a date or a unix timestamp.
+type timeFlag time.Time
+
+func (f *timeFlag) String() string {
+	if time.Time(*f).IsZero() {
+		return ""
+	}
+
+	return time.Time(*f).Format(time.RFC3339)
+}
+
+func (f *timeFlag) Set(v string) error {
+	t, err := parseTime(v)
+	if err != nil {
+		return err
+	}
+
+	*f = timeFlag(t)
+	return nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, nil
+	}
+
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC 3339, YYYY-MM-DD or a unix timestamp", v)
+}
+
+// durationFlag parses relative durations, supporting days ("7d") and weeks ("2w")
+// in addition to the units of time.ParseDuration.
+type durationFlag time.Duration
+
+func (f *durationFlag) String() string {
+	if *f == 0 {
+		return ""
+	}
+
+	return time.Duration(*f).String()
+}
+
+func (f *durationFlag) Set(v string) error {
+	d, err := parseDuration(v)
+	if err != nil {
+		return err
+	}
+
+	*f = durationFlag(d)
+	return nil
+}
+
+func parseDuration(v string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if !strings.HasSuffix(v, suffix) {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSuffix(v, suffix), 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+
+		return time.Duration(n * float64(unit)), nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, use i.e. 12h, 7d or 2w", v)
+	}
+
+	return d, nil
+}
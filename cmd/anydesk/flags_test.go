@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	a := assert.New(t)
+
+	cases := map[string]time.Duration{
+		"7d":   7 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"1.5d": 36 * time.Hour,
+		"12h":  12 * time.Hour,
+		"90m":  90 * time.Minute,
+	}
+
+	for v, expected := range cases {
+		d, err := parseDuration(v)
+		a.NoError(err, v)
+		a.Equal(expected, d, v)
+	}
+
+	for _, v := range []string{"", "d", "-1d", "7x", "-5h"} {
+		_, err := parseDuration(v)
+		a.Error(err, v)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	a := assert.New(t)
+
+	v, err := parseTime("2020-05-26T14:50:26Z")
+	a.NoError(err)
+	a.Equal(int64(1590504626), v.Unix())
+
+	v, err = parseTime("1590504626")
+	a.NoError(err)
+	a.Equal(int64(1590504626), v.Unix())
+
+	v, err = parseTime("2020-05-26")
+	a.NoError(err)
+	a.Equal(time.Date(2020, 5, 26, 0, 0, 0, 0, time.Local), v)
+
+	_, err = parseTime("yesterday")
+	a.Error(err)
+}
+
+func TestPaginationFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	p := paginationFlags(fs)
+
+	a := assert.New(t)
+	a.NoError(fs.Parse([]string{"-offset", "10", "-limit", "5", "-sort", "alias", "-order", "ASC"}))
+	a.Equal(&anydesk.PaginationOptions{Offset: 10, Limit: 5, Sort: "alias", Order: anydesk.OrderAsc}, p)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	paginationFlags(fs)
+	a.Error(fs.Parse([]string{"-order", "sideways"}))
+}
@@ -0,0 +1,195 @@
+// Command anydesk queries the AnyDesk REST API from the command line.
+//
+//   anydesk [-profile name] [-format table|json|ndjson|csv] [-fields a,b,c] [-har path] <command> [flags] [arguments]
+//
+// Commands:
+//
+//   auth                         test the credentials against "/auth"
+//   sysinfo                      show license and system information
+//   clients list                 list clients, see "clients list -h" for filters
+//   clients show <cid>           show details and last sessions of a client
+//   sessions list                list sessions, see "sessions list -h" for filters
+//   sessions comment <sid> <text> set or, with an empty text, remove a session comment
+//
+// Results are rendered as table by default, use -format to switch to json, ndjson or csv
+// and -fields to select and order the rendered fields, i.e. "-fields cid,alias,online".
+// With -har the http exchanges with the API are written as HAR file to the given path,
+// the API password and request signatures are redacted.
+//
+// Credentials are read from the ANYDESK_LICENSE_ID, ANYDESK_API_PASSWORD and the optional
+// ANYDESK_API_ENDPOINT environment variables. Alternatively a named profile can be selected
+// with -profile or ANYDESK_PROFILE, which is read from "anydesk/config.json" in the user
+// config directory or the file given by ANYDESK_CONFIG, see anydesk.Config for the format:
+//
+//   {
+//     "default_profile": "default",
+//     "profiles": {
+//       "default": {"license_id": "...", "api_password_env": "ANYDESK_DEFAULT_PASSWORD"},
+//       "enterprise": {"license_id": "...", "api_password_file": "...", "api_endpoint": "https://yourinstance:8081"}
+//     }
+//   }
+//
+// The environment variables override the respective values of the selected profile.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/adrianrudnik/anydesk/format"
+)
+
+const usage = `Usage: anydesk [global flags] <command> [flags] [arguments]
+
+Commands:
+  auth                          test the credentials against "/auth"
+  sysinfo                       show license and system information
+  clients list                  list clients
+  clients show <cid>            show details and last sessions of a client
+  sessions list                 list sessions
+  sessions comment <sid> <text> set or, with an empty text, remove a session comment
+
+Global flags:
+`
+
+// cli contains the global state shared by all commands.
+type cli struct {
+	api      *anydesk.API
+	out      io.Writer
+	renderer *format.Renderer
+}
+
+// now returns the current time of the API clock, i.e. for relative time ranges.
+func (c *cli) now() time.Time {
+	if c.api.Clock == nil {
+		return anydesk.SystemClock.Now()
+	}
+
+	return c.api.Clock.Now()
+}
+
+// command executes a single (sub)command with the remaining arguments.
+type command func(c *cli, args []string) error
+
+var commands = map[string]map[string]command{
+	"auth":    {"": runAuth},
+	"sysinfo": {"": runSysinfo},
+	"clients": {
+		"list": runClientsList,
+		"show": runClientsShow,
+	},
+	"sessions": {
+		"list":    runSessionsList,
+		"comment": runSessionsComment,
+	},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "anydesk: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("anydesk", flag.ContinueOnError)
+	profile := fs.String("profile", os.Getenv("ANYDESK_PROFILE"), "name of the config profile to use")
+	output := fs.String("format", string(format.Table), "output format, table, json, ndjson or csv")
+	fields := fs.String("fields", "", "comma separated list of fields to render, i.e. cid,alias,online")
+	har := fs.String("har", "", "write the http exchanges as HAR file to the given path")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := format.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	subs, ok := commands[args[0]]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	cmd, ok := subs[""]
+	args = args[1:]
+
+	if !ok {
+		if len(args) == 0 {
+			return fmt.Errorf("missing subcommand for %q", fs.Arg(0))
+		}
+
+		if cmd, ok = subs[args[0]]; !ok {
+			return fmt.Errorf("unknown subcommand %q for %q", args[0], fs.Arg(0))
+		}
+
+		args = args[1:]
+	}
+
+	api, err := anydesk.NewAPIFromProfile(*profile)
+	if err != nil {
+		return err
+	}
+
+	if *har != "" {
+		api.HAR = anydesk.NewHARRecorder()
+	}
+
+	err = cmd(&cli{
+		api:      api,
+		out:      out,
+		renderer: format.NewRenderer(f, format.ParseFields(*fields)),
+	}, args)
+
+	if api.HAR != nil {
+		if werr := api.HAR.WriteFile(*har); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
+	return err
+}
+
+func runAuth(c *cli, args []string) error {
+	resp, err := anydesk.NewAuthenticationRequest().Do(c.api)
+	if err != nil {
+		return err
+	}
+
+	if resp.Result != "success" {
+		return fmt.Errorf("authentication failed: %s %s", resp.Code, resp.Error)
+	}
+
+	return c.renderer.Authentication(c.out, resp)
+}
+
+func runSysinfo(c *cli, args []string) error {
+	resp, err := anydesk.NewSysinfoRequest().Do(c.api)
+	if err != nil {
+		return err
+	}
+
+	return c.renderer.Sysinfo(c.out, resp)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/adrianrudnik/anydesk/anydesktest"
+	"github.com/adrianrudnik/anydesk/format"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer starts a stub server and points the credential environment variables to it.
+func newTestServer(t *testing.T) *anydesktest.Server {
+	s := anydesktest.NewServer("TEST_LICENSE", "TEST_PASSWORD")
+
+	s.Clients = []anydesk.ClientNode{
+		{ClientID: 100, Alias: "alpha@ad", Online: true},
+		{ClientID: 200, Alias: "beta@ad"},
+	}
+
+	s.Sessions = []anydesk.SessionNode{
+		{SessionID: "S1", Source: &anydesk.ClientSlimNode{ClientID: 100}, Target: &anydesk.ClientSlimNode{ClientID: 200}},
+		{SessionID: "S2", Source: &anydesk.ClientSlimNode{ClientID: 200}, Target: &anydesk.ClientSlimNode{ClientID: 100}},
+	}
+
+	os.Setenv("ANYDESK_LICENSE_ID", s.LicenseID)
+	os.Setenv("ANYDESK_API_PASSWORD", s.APIPassword)
+	os.Setenv("ANYDESK_API_ENDPOINT", s.URL)
+
+	return s
+}
+
+func TestRun_Auth(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, run([]string{"auth"}, out))
+	assert.Contains(t, out.String(), "LICENSE-ID  TEST_LICENSE")
+
+	out.Reset()
+	assert.NoError(t, run([]string{"-format", "json", "-fields", "license-id", "auth"}, out))
+	assert.JSONEq(t, `{"license-id": "TEST_LICENSE"}`, out.String())
+}
+
+func TestRun_ClientsList(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, run([]string{"-format", "json", "clients", "list", "-online"}, out))
+
+	var list []anydesk.ClientNode
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &list))
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(100), list[0].ClientID)
+
+	out.Reset()
+	assert.NoError(t, run([]string{"-format", "csv", "-fields", "alias,cid", "clients", "list", "-sort", "cid"}, out))
+	assert.Equal(t, "alias,cid\nbeta@ad,200\nalpha@ad,100\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, run([]string{"-format", "csv", "-fields", "cid", "clients", "list", "-offline", "-alias-prefix", "beta"}, out))
+	assert.Equal(t, "cid\n200\n", out.String())
+
+	assert.Error(t, run([]string{"clients", "list", "-online", "-offline"}, out))
+	assert.Error(t, run([]string{"-fields", "nope", "clients", "list"}, out))
+	assert.Error(t, run([]string{"-format", "xml", "clients", "list"}, out))
+}
+
+func TestRun_ClientsShow(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, run([]string{"clients", "show", "200"}, out))
+	assert.Contains(t, out.String(), "beta@ad")
+	assert.Contains(t, out.String(), "Last sessions:")
+
+	assert.Error(t, run([]string{"clients", "show", "abc"}, out))
+	assert.Error(t, run([]string{"clients", "show"}, out))
+}
+
+func TestRun_SessionsList(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, run([]string{"-format", "ndjson", "-fields", "sid,from,to", "sessions", "list", "-cid", "100", "-direction", "out", "-limit", "10"}, out))
+	assert.Equal(t, `{"sid":"S1","from":100,"to":200}`+"\n", out.String())
+
+	assert.Error(t, run([]string{"sessions", "list", "-since", "7d", "-from", "2020-01-01"}, out))
+}
+
+func TestRunSessionsList_Since(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	now := time.Unix(1590504000, 0)
+	s.Sessions[0].StartTimestamp = now.Add(-time.Hour).Unix()
+	s.Sessions[1].StartTimestamp = now.Add(-72 * time.Hour).Unix()
+
+	clock := anydesktest.NewFakeClock(now)
+	s.Clock = clock
+
+	api := s.API()
+	api.Clock = clock
+
+	out := &bytes.Buffer{}
+	c := &cli{api: api, out: out, renderer: format.NewRenderer(format.CSV, format.ParseFields("sid"))}
+
+	assert.NoError(t, runSessionsList(c, []string{"-since", "1d"}))
+	assert.Equal(t, "sid\nS1\n", out.String())
+}
+
+func TestRun_SessionsComment(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	assert.NoError(t, run([]string{"sessions", "comment", "S2", "remote", "support"}, ioutil.Discard))
+	assert.Equal(t, "remote support", s.Sessions[1].Comment)
+
+	assert.NoError(t, run([]string{"sessions", "comment", "S2"}, ioutil.Discard))
+	assert.Equal(t, "", s.Sessions[1].Comment)
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	assert.Error(t, run([]string{}, out))
+	assert.Error(t, run([]string{"nope"}, out))
+	assert.Error(t, run([]string{"clients"}, out))
+	assert.Error(t, run([]string{"clients", "nope"}, out))
+}
+
+func TestRun_Profile(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	config := `{"profiles": {"test": {"license_id": "TEST_LICENSE", "api_password": "TEST_PASSWORD", "api_endpoint": "` + s.URL + `"}}}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+	os.Setenv("ANYDESK_CONFIG", path)
+	os.Unsetenv("ANYDESK_API_PASSWORD")
+	defer os.Unsetenv("ANYDESK_CONFIG")
+
+	out := &bytes.Buffer{}
+	assert.Error(t, run([]string{"auth"}, out))
+	assert.NoError(t, run([]string{"-profile", "test", "auth"}, out))
+	assert.Error(t, run([]string{"-profile", "unknown", "auth"}, out))
+}
+
+func TestRun_HAR(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "auth.har")
+	assert.NoError(t, run([]string{"-har", path, "auth"}, ioutil.Discard))
+
+	raw, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), "TEST_PASSWORD")
+
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					URL string `json:"url"`
+				} `json:"request"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+
+	assert.NoError(t, json.Unmarshal(raw, &har))
+	assert.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, s.URL+"/auth", har.Log.Entries[0].Request.URL)
+}
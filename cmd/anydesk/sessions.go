@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+func runSessionsList(c *cli, args []string) error {
+	var (
+		direction directionFlag
+		from      timeFlag
+		to        timeFlag
+		since     durationFlag
+	)
+
+	fs := flag.NewFlagSet("sessions list", flag.ContinueOnError)
+	cid := fs.Int64("cid", 0, "only list sessions of the given client ID")
+	fs.Var(&direction, "direction", "session direction, in, out or inout")
+	fs.Var(&from, "from", "only list sessions after the given time")
+	fs.Var(&to, "to", "only list sessions up to the given time")
+	fs.Var(&since, "since", "only list sessions of the given past duration, i.e. 12h, 7d or 2w")
+	pagination := paginationFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	search := &anydesk.SessionListSearch{
+		ClientID:  *cid,
+		Direction: anydesk.SessionDirection(direction),
+		TimeFrom:  time.Time(from),
+		TimeTo:    time.Time(to),
+	}
+
+	if since > 0 {
+		if !search.TimeFrom.IsZero() {
+			return errors.New("-since and -from can not be combined")
+		}
+
+		search.TimeFrom = c.now().Add(-time.Duration(since))
+	}
+
+	req := anydesk.NewSessionListRequest(search)
+	req.PaginationOptions = pagination
+
+	resp, err := req.Do(c.api)
+	if err != nil {
+		var noResults *anydesk.APINoResultsError
+		if errors.As(err, &noResults) {
+			return c.renderer.Sessions(c.out, nil)
+		}
+
+		return err
+	}
+
+	return c.renderer.Sessions(c.out, resp.List)
+}
+
+func runSessionsComment(c *cli, args []string) error {
+	fs := flag.NewFlagSet("sessions comment", flag.ContinueOnError)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 {
+		return errors.New("usage: sessions comment <sid> <text>")
+	}
+
+	return anydesk.NewSessionCommentChangeRequest(fs.Arg(0), strings.Join(fs.Args()[1:], " ")).Do(c.api)
+}
@@ -0,0 +1,85 @@
+package anydesk
+
+import (
+	"fmt"
+	"sync"
+)
+
+// flightGroup deduplicates identical requests in flight, so a single http request serves all callers.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+
+	// Called when a caller waits for a request in flight, used by tests
+	joined func(key string)
+}
+
+// flight is a single request in flight and its outcome.
+type flight struct {
+	done        chan struct{}
+	body        []byte
+	meta        ResponseMeta
+	unavailable bool
+	err         error
+}
+
+// do executes fn once per key at a time. Callers arriving while it runs wait for its outcome
+// and receive their own copy of the body and meta. If fn panics, waiting callers receive an
+// error and the panic is passed on to the caller that executed fn.
+func (g *flightGroup) do(
+	key string,
+	fn func() ([]byte, ResponseMeta, bool, error),
+) (body []byte, meta ResponseMeta, unavailable bool, err error) {
+	g.mu.Lock()
+
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+
+		if g.joined != nil {
+			g.joined(key)
+		}
+
+		<-f.done
+
+		if f.body != nil {
+			body = append([]byte(nil), f.body...)
+		}
+
+		return body, f.meta.clone(), f.unavailable, f.err
+	}
+
+	if g.calls == nil {
+		g.calls = map[string]*flight{}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		r := recover()
+		if r != nil {
+			f.body = nil
+			f.err = fmt.Errorf("coalesced request panicked: %v", r)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		close(f.done)
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	f.body, f.meta, f.unavailable, f.err = fn()
+
+	// The leader keeps its own copy, waiters copy from the shared one
+	if f.body != nil {
+		body = append([]byte(nil), f.body...)
+	}
+
+	return body, f.meta.clone(), f.unavailable, f.err
+}
@@ -0,0 +1,171 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countJoined counts the callers of the group that wait for a request in flight.
+func countJoined(g *flightGroup) *int32 {
+	var joined int32
+
+	g.joined = func(string) {
+		atomic.AddInt32(&joined, 1)
+	}
+
+	return &joined
+}
+
+// waitForJoined blocks until the given number of callers joined a request in flight.
+func waitForJoined(joined *int32, waiters int) {
+	for atomic.LoadInt32(joined) < int32(waiters) {
+		runtime.Gosched()
+	}
+}
+
+func TestFlightGroup(t *testing.T) {
+	g := &flightGroup{}
+	joined := countJoined(g)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var calls int32
+	var wg sync.WaitGroup
+	bodies := make([][]byte, 5)
+
+	for i := range bodies {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			bodies[i], _, _, _ = g.do("GET /sysinfo", func() ([]byte, ResponseMeta, bool, error) {
+				atomic.AddInt32(&calls, 1)
+				close(started)
+				<-release
+
+				return []byte("body"), ResponseMeta{StatusCode: 200}, false, nil
+			})
+		}(i)
+
+		// Ensure the first call is in flight before the others arrive
+		if i == 0 {
+			<-started
+		}
+	}
+
+	waitForJoined(joined, len(bodies)-1)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+
+	for _, b := range bodies {
+		assert.Equal(t, []byte("body"), b)
+	}
+
+	// every caller owns its copy
+	bodies[0][0] = 'B'
+	assert.Equal(t, []byte("body"), bodies[1])
+
+	// after completion the next call executes again
+	_, _, _, _ = g.do("GET /sysinfo", func() ([]byte, ResponseMeta, bool, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, ResponseMeta{}, false, nil
+	})
+
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestFlightGroup_Panic(t *testing.T) {
+	g := &flightGroup{}
+	joined := countJoined(g)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	panicked := make(chan struct{})
+	waited := make(chan error)
+
+	go func() {
+		defer func() {
+			assert.Equal(t, "boom", recover())
+			close(panicked)
+		}()
+
+		_, _, _, _ = g.do("GET /sysinfo", func() ([]byte, ResponseMeta, bool, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	<-started
+
+	go func() {
+		_, _, _, err := g.do("GET /sysinfo", func() ([]byte, ResponseMeta, bool, error) {
+			return nil, ResponseMeta{}, false, nil
+		})
+		waited <- err
+	}()
+
+	waitForJoined(joined, 1)
+	close(release)
+
+	// the waiter receives an error instead of a false success, the leader panics
+	assert.EqualError(t, <-waited, "coalesced request panicked: boom")
+	<-panicked
+}
+
+func TestAPI_Do_CoalesceRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+
+		data, err := ioutil.ReadFile("./_tests/sysinfo.json")
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.CoalesceRequests = true
+	joined := countJoined(&api.internal().flights)
+
+	var wg sync.WaitGroup
+	responses := make([]*SysinfoResponse, 10)
+
+	for i := range responses {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			resp, err := NewSysinfoRequest().Do(api)
+			assert.NoError(t, err)
+
+			responses[i] = resp
+		}(i)
+	}
+
+	waitForJoined(joined, len(responses)-1)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// each caller decoded its own response
+	assert.Equal(t, "AnyDesk REST", responses[0].Name)
+	assert.False(t, responses[0] == responses[1])
+}
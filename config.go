@@ -0,0 +1,303 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultProfileName is used when no profile name is given and the config does not define a default.
+const DefaultProfileName = "default"
+
+// Config contains named profiles for multiple licenses and endpoints.
+//
+// It is stored as JSON, by default in "anydesk/config.json" of the user config directory:
+//
+//   {
+//     "default_profile": "professional",
+//     "profiles": {
+//       "professional": {
+//         "license_id": "1438129266231705",
+//         "api_password_env": "ANYDESK_PRO_PASSWORD",
+//         "timeout": "10s",
+//         "rate_limit": {"requests_per_second": 2, "burst": 5}
+//       },
+//       "enterprise": {
+//         "license_id": "1438129266231706",
+//         "api_password_file": "/run/secrets/anydesk",
+//         "api_endpoint": "https://yourinstance:8081",
+//         "tls": {"ca_file": "/etc/ssl/internal-ca.pem"}
+//       }
+//     }
+//   }
+type Config struct {
+	// Name of the profile used when no profile name is given.
+	DefaultProfile string `json:"default_profile"`
+
+	// Profiles by name.
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Profile contains everything required to compose an API configuration for a single license.
+type Profile struct {
+	// API license ID as provided by AnyDesk support.
+	LicenseID string `json:"license_id"`
+
+	// API password in plain text. Prefer APIPasswordEnv or APIPasswordFile.
+	APIPassword string `json:"api_password,omitempty"`
+
+	// Name of the environment variable containing the API password.
+	APIPasswordEnv string `json:"api_password_env,omitempty"`
+
+	// Path of the file containing the API password, surrounding whitespace is ignored.
+	APIPasswordFile string `json:"api_password_file,omitempty"`
+
+	// API endpoint, defaults to DefaultApiEndpoint.
+	APIEndpoint string `json:"api_endpoint,omitempty"`
+
+	// Ordered list of endpoints for highly-available deployments, see API.Endpoints.
+	APIEndpoints []string `json:"api_endpoints,omitempty"`
+
+	// Timeout of a single http request, i.e. "10s". Zero means no timeout.
+	Timeout ConfigDuration `json:"timeout,omitempty"`
+
+	// Optional TLS settings, i.e. for enterprise instances with an internal CA.
+	TLS *ProfileTLS `json:"tls,omitempty"`
+
+	// Optional rate limit for the API requests.
+	RateLimit *ProfileRateLimit `json:"rate_limit,omitempty"`
+}
+
+// ProfileTLS contains the TLS settings of a profile.
+type ProfileTLS struct {
+	// Path of a PEM file with CA certificates to trust, in addition to the system pool.
+	CAFile string `json:"ca_file,omitempty"`
+
+	// SPKI pins, see TLSOptions.Pins.
+	Pins []string `json:"pins,omitempty"`
+
+	// Paths of the PEM encoded client certificate and key.
+	ClientCertFile string `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string `json:"client_key_file,omitempty"`
+
+	// Explicit proxy URL, i.e. "http://proxy:3128".
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// Disables the certificate verification. Only use for testing.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// ProfileRateLimit contains the rate limit settings of a profile.
+type ProfileRateLimit struct {
+	// Average number of requests per second.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+
+	// Maximum number of requests sent in a burst.
+	Burst int `json:"burst"`
+}
+
+// ConfigDuration is a time.Duration that is stored as string, i.e. "10s" or "1m30s".
+type ConfigDuration time.Duration
+
+// MarshalJSON encodes the duration as string.
+func (d ConfigDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes the duration from a string like "10s" or from a number of seconds.
+func (d *ConfigDuration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case float64:
+		*d = ConfigDuration(t * float64(time.Second))
+	case string:
+		parsed, err := time.ParseDuration(t)
+		if err != nil {
+			return err
+		}
+
+		*d = ConfigDuration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+
+	return nil
+}
+
+// DefaultConfigPath returns the path of the config file, as given by the ANYDESK_CONFIG
+// environment variable or "anydesk/config.json" in the user config directory.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv("ANYDESK_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "anydesk", "config.json"), nil
+}
+
+// LoadConfig reads the config from the given JSON file.
+func LoadConfig(path string) (c *Config, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	c = &Config{}
+
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return
+}
+
+// Profile returns a copy of the named profile with environment overrides applied.
+// An empty name selects the profile given by ANYDESK_PROFILE, the default profile of the config
+// or DefaultProfileName. If no name was given and the selected profile does not exist, a profile
+// composed of environment variables alone is returned.
+//
+// The environment variables ANYDESK_LICENSE_ID, ANYDESK_API_PASSWORD and ANYDESK_API_ENDPOINT
+// override the respective profile values.
+func (c *Config) Profile(name string) (*Profile, error) {
+	explicit := name != ""
+
+	if name == "" {
+		name = os.Getenv("ANYDESK_PROFILE")
+		explicit = name != ""
+	}
+
+	if name == "" {
+		name = c.DefaultProfile
+		explicit = name != ""
+	}
+
+	if name == "" {
+		name = DefaultProfileName
+	}
+
+	p := &Profile{}
+
+	if found, ok := c.Profiles[name]; ok {
+		*p = *found
+	} else if explicit {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	if v := os.Getenv("ANYDESK_LICENSE_ID"); v != "" {
+		p.LicenseID = v
+	}
+
+	if v := os.Getenv("ANYDESK_API_PASSWORD"); v != "" {
+		p.APIPassword = v
+		p.APIPasswordEnv = ""
+		p.APIPasswordFile = ""
+	}
+
+	if v := os.Getenv("ANYDESK_API_ENDPOINT"); v != "" {
+		p.APIEndpoint = v
+		p.APIEndpoints = nil
+	}
+
+	return p, nil
+}
+
+// Password resolves the API password from the configured source.
+func (p *Profile) Password() (string, error) {
+	switch {
+	case p.APIPasswordEnv != "":
+		v := os.Getenv(p.APIPasswordEnv)
+		if v == "" {
+			return "", fmt.Errorf("environment variable %s is empty", p.APIPasswordEnv)
+		}
+
+		return v, nil
+	case p.APIPasswordFile != "":
+		data, err := ioutil.ReadFile(p.APIPasswordFile)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(string(data)), nil
+	case p.APIPassword != "":
+		return p.APIPassword, nil
+	default:
+		return "", errors.New("no API password configured")
+	}
+}
+
+// API returns a ready to use API configuration for the profile.
+func (p *Profile) API() (*API, error) {
+	if p.LicenseID == "" {
+		return nil, errors.New("no license ID configured")
+	}
+
+	password, err := p.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	api := NewAPI(p.LicenseID, password)
+	api.HTTPClient.Timeout = time.Duration(p.Timeout)
+
+	if p.APIEndpoint != "" {
+		api.APIEndpoint = p.APIEndpoint
+	}
+
+	api.Endpoints = p.APIEndpoints
+
+	if p.TLS != nil {
+		api.TLS = &TLSOptions{
+			CAFile:             p.TLS.CAFile,
+			Pins:               p.TLS.Pins,
+			ClientCertFile:     p.TLS.ClientCertFile,
+			ClientKeyFile:      p.TLS.ClientKeyFile,
+			ProxyURL:           p.TLS.ProxyURL,
+			InsecureSkipVerify: p.TLS.InsecureSkipVerify,
+		}
+	}
+
+	if p.RateLimit != nil && p.RateLimit.RequestsPerSecond > 0 {
+		api.RateLimiter = NewRateLimiter(p.RateLimit.RequestsPerSecond, p.RateLimit.Burst)
+	}
+
+	return api, nil
+}
+
+// NewAPIFromProfile returns the API configuration of the named profile of the default config file.
+// See Config.Profile for the selection of the profile and environment overrides. Without config
+// file, the API configuration is composed of environment variables alone.
+func NewAPIFromProfile(name string) (*API, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := LoadConfig(path)
+	if os.IsNotExist(err) {
+		c, err = &Config{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.Profile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return p.API()
+}
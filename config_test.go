@@ -0,0 +1,202 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `{
+  "default_profile": "professional",
+  "profiles": {
+    "professional": {
+      "license_id": "1438129266231705",
+      "api_password": "UYETICGU2CT3KES",
+      "timeout": "10s",
+      "rate_limit": {"requests_per_second": 2, "burst": 5}
+    },
+    "enterprise": {
+      "license_id": "1438129266231706",
+      "api_password_env": "TEST_ENTERPRISE_PASSWORD",
+      "api_endpoints": ["https://yourinstance:8081", "https://yourstandby:8081"],
+      "timeout": 30,
+      "tls": {"insecure_skip_verify": true}
+    }
+  }
+}`
+
+// writeTestConfig writes the given config to a temporary file and points ANYDESK_CONFIG to it.
+func writeTestConfig(t *testing.T, config string) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+
+	path = filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+	os.Setenv("ANYDESK_CONFIG", path)
+
+	return path, func() {
+		os.Unsetenv("ANYDESK_CONFIG")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path, cleanup := writeTestConfig(t, testConfig)
+	defer cleanup()
+
+	c, err := LoadConfig(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "professional", c.DefaultProfile)
+	assert.Len(t, c.Profiles, 2)
+	assert.Equal(t, ConfigDuration(10*time.Second), c.Profiles["professional"].Timeout)
+	assert.Equal(t, ConfigDuration(30*time.Second), c.Profiles["enterprise"].Timeout)
+	assert.Equal(t, 5, c.Profiles["professional"].RateLimit.Burst)
+	assert.True(t, c.Profiles["enterprise"].TLS.InsecureSkipVerify)
+
+	_, err = LoadConfig(filepath.Join(filepath.Dir(path), "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfigDuration_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(ConfigDuration(90 * time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, `"1m30s"`, string(data))
+
+	var d ConfigDuration
+	assert.Error(t, json.Unmarshal([]byte(`"soon"`), &d))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &d))
+}
+
+func TestConfig_Profile(t *testing.T) {
+	c := &Config{}
+	assert.NoError(t, json.Unmarshal([]byte(testConfig), c))
+
+	// default profile of the config
+	p, err := c.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "1438129266231705", p.LicenseID)
+
+	// explicit name
+	p, err = c.Profile("enterprise")
+	assert.NoError(t, err)
+	assert.Equal(t, "1438129266231706", p.LicenseID)
+
+	// profile from the environment
+	os.Setenv("ANYDESK_PROFILE", "enterprise")
+	p, err = c.Profile("")
+	os.Unsetenv("ANYDESK_PROFILE")
+	assert.NoError(t, err)
+	assert.Equal(t, "1438129266231706", p.LicenseID)
+
+	_, err = c.Profile("unknown")
+	assert.Error(t, err)
+
+	// environment overrides do not change the stored profile
+	os.Setenv("ANYDESK_API_PASSWORD", "OVERRIDE")
+	os.Setenv("ANYDESK_API_ENDPOINT", "http://localhost:8080")
+	p, err = c.Profile("enterprise")
+	os.Unsetenv("ANYDESK_API_PASSWORD")
+	os.Unsetenv("ANYDESK_API_ENDPOINT")
+	assert.NoError(t, err)
+	assert.Equal(t, "OVERRIDE", p.APIPassword)
+	assert.Empty(t, p.APIPasswordEnv)
+	assert.Equal(t, "http://localhost:8080", p.APIEndpoint)
+	assert.Empty(t, p.APIEndpoints)
+	assert.Len(t, c.Profiles["enterprise"].APIEndpoints, 2)
+
+	// without profiles only the environment is used
+	os.Setenv("ANYDESK_LICENSE_ID", "ENV_LICENSE")
+	p, err = (&Config{}).Profile("")
+	os.Unsetenv("ANYDESK_LICENSE_ID")
+	assert.NoError(t, err)
+	assert.Equal(t, "ENV_LICENSE", p.LicenseID)
+}
+
+func TestProfile_Password(t *testing.T) {
+	p := &Profile{APIPassword: "PLAIN"}
+	password, err := p.Password()
+	assert.NoError(t, err)
+	assert.Equal(t, "PLAIN", password)
+
+	p = &Profile{APIPasswordEnv: "TEST_PROFILE_PASSWORD"}
+	_, err = p.Password()
+	assert.Error(t, err)
+
+	os.Setenv("TEST_PROFILE_PASSWORD", "FROM_ENV")
+	defer os.Unsetenv("TEST_PROFILE_PASSWORD")
+
+	password, err = p.Password()
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM_ENV", password)
+
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "password")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("FROM_FILE\n"), 0600))
+
+	p = &Profile{APIPasswordFile: file}
+	password, err = p.Password()
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM_FILE", password)
+
+	_, err = (&Profile{}).Password()
+	assert.Error(t, err)
+}
+
+func TestProfile_API(t *testing.T) {
+	c := &Config{}
+	assert.NoError(t, json.Unmarshal([]byte(testConfig), c))
+
+	api, err := c.Profiles["professional"].API()
+	assert.NoError(t, err)
+	assert.Equal(t, "1438129266231705", api.LicenseID)
+	assert.Equal(t, "UYETICGU2CT3KES", api.APIPassword)
+	assert.Equal(t, DefaultApiEndpoint, api.APIEndpoint)
+	assert.Equal(t, 10*time.Second, api.HTTPClient.Timeout)
+	assert.NotNil(t, api.RateLimiter)
+
+	os.Setenv("TEST_ENTERPRISE_PASSWORD", "ENTERPRISE")
+	defer os.Unsetenv("TEST_ENTERPRISE_PASSWORD")
+
+	api, err = c.Profiles["enterprise"].API()
+	assert.NoError(t, err)
+	assert.Equal(t, "ENTERPRISE", api.APIPassword)
+	assert.Equal(t, []string{"https://yourinstance:8081", "https://yourstandby:8081"}, api.Endpoints)
+	assert.Nil(t, api.RateLimiter)
+	assert.True(t, api.TLS.InsecureSkipVerify)
+
+	_, err = (&Profile{APIPassword: "X"}).API()
+	assert.Error(t, err)
+}
+
+func TestNewAPIFromProfile(t *testing.T) {
+	_, cleanup := writeTestConfig(t, testConfig)
+	defer cleanup()
+
+	api, err := NewAPIFromProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "1438129266231705", api.LicenseID)
+
+	_, err = NewAPIFromProfile("unknown")
+	assert.Error(t, err)
+
+	// a missing config file falls back to the environment
+	os.Setenv("ANYDESK_CONFIG", filepath.Join(os.TempDir(), "anydesk-missing", "config.json"))
+	os.Setenv("ANYDESK_LICENSE_ID", "ENV_LICENSE")
+	os.Setenv("ANYDESK_API_PASSWORD", "ENV_PASSWORD")
+	defer os.Unsetenv("ANYDESK_LICENSE_ID")
+	defer os.Unsetenv("ANYDESK_API_PASSWORD")
+
+	api, err = NewAPIFromProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "ENV_LICENSE", api.LicenseID)
+	assert.Equal(t, "ENV_PASSWORD", api.APIPassword)
+}
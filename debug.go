@@ -1,8 +1,13 @@
 package anydesk
 
 import (
+	"crypto/tls"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 var (
@@ -26,17 +31,121 @@ type DebugInfo struct {
 	// The full request URL sent by the http request.
 	RequestURL *url.URL
 
-	// The http.Request used for the request.
+	// A copy of the http.Request used for the request, with the signature of the "Authorization" header redacted.
 	Request *http.Request
 
 	// The plain request body sent to the API.
 	RequestBody []byte
 
 	// The http.Response received by the http request.
+	// Its body is already consumed, use ResponseBody instead.
 	Response *http.Response
 
-	// The plain response body received by the API.
+	// The plain response body received by the API. Not available for streamed responses.
 	ResponseBody []byte
+
+	// Duration of the phases of the http exchange.
+	Timings DebugTimings
+}
+
+// DebugTimings contains the duration of the phases of a http exchange.
+// Phases that did not happen, i.e. connecting for a reused connection, are zero.
+type DebugTimings struct {
+	// Time the request was started.
+	Started time.Time
+
+	// Resolving the host name.
+	DNS time.Duration
+
+	// Establishing the TCP connection.
+	Connect time.Duration
+
+	// TLS handshake.
+	TLS time.Duration
+
+	// Writing the request.
+	Send time.Duration
+
+	// Waiting for the first byte of the response.
+	Wait time.Duration
+
+	// Reading the response body.
+	Receive time.Duration
+
+	// The whole exchange.
+	Total time.Duration
+}
+
+// debugTrace measures the timings of a http exchange.
+type debugTrace struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wrote        time.Time
+	firstByte    time.Time
+}
+
+func newDebugTrace() *debugTrace {
+	return &debugTrace{start: time.Now()}
+}
+
+// mark sets the given time to now, but only once.
+func (t *debugTrace) mark(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+func (t *debugTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:         func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { t.mark(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wrote) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+// timings returns the measured timings, the exchange ends now.
+func (t *debugTrace) timings() DebugTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	end := time.Now()
+
+	return DebugTimings{
+		Started: t.start,
+		DNS:     between(t.dnsStart, t.dnsDone),
+		Connect: between(t.connectStart, t.connectDone),
+		TLS:     between(t.tlsStart, t.tlsDone),
+		Send:    between(t.gotConn, t.wrote),
+		Wait:    between(t.wrote, t.firstByte),
+		Receive: between(t.firstByte, end),
+		Total:   end.Sub(t.start),
+	}
+}
+
+// between returns the duration between both times, zero if either is missing.
+func between(from time.Time, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+
+	return to.Sub(from)
 }
 
 func newDebugInfo() *DebugInfo {
@@ -58,3 +167,22 @@ func (r *BaseRequest) GetDebug() *DebugInfo {
 	}
 	return r.debug
 }
+
+// redactRequest returns a copy of the request for debugging, without the request signature or body.
+// The license ID and timestamp of the "Authorization" header are kept.
+func redactRequest(r *http.Request) *http.Request {
+	c := r.Clone(r.Context())
+	c.Body = http.NoBody
+
+	if auth := c.Header.Get("Authorization"); auth != "" {
+		if i := strings.LastIndex(auth, ":"); i >= 0 {
+			auth = auth[:i+1] + Redacted
+		} else {
+			auth = Redacted
+		}
+
+		c.Header.Set("Authorization", auth)
+	}
+
+	return c
+}
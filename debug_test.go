@@ -3,8 +3,11 @@ package anydesk
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -52,6 +55,45 @@ func TestApi_DebugEnabled(t *testing.T) {
 	a.Equal("/test", req.GetDebug().RequestURL.Path)
 	a.Equal("demo=123", req.GetDebug().RequestURL.RawQuery)
 	a.Equal("127.0.0.1", req.GetDebug().RequestURL.Hostname())
+	a.Regexp(`^AD :\d+:\[REDACTED\]$`, req.GetDebug().Request.Header.Get("Authorization"))
 
 	SetDebug(false)
 }
+
+func TestApi_DebugRedactsToken(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	var auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		auth = req.Header.Get("Authorization")
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	req := &BaseRequest{Method: "GET", Resource: "/test"}
+	_, err := NewAPITestClient(t, server, "", "").Do(req)
+
+	a := assert.New(t)
+	a.NoError(err)
+
+	token := auth[strings.LastIndex(auth, ":")+1:]
+	a.NotEmpty(token)
+
+	d := req.GetDebug()
+	if a.NotNil(d.Response) && a.NotNil(d.Response.Request) {
+		a.Same(d.Request, d.Response.Request)
+	}
+
+	for _, h := range []http.Header{d.Request.Header, d.Response.Request.Header, d.Response.Header} {
+		for name, values := range h {
+			for _, v := range values {
+				a.NotContains(v, token, name)
+			}
+		}
+	}
+
+	a.NotContains(d.RequestURL.String(), token)
+	a.NotContains(string(d.ResponseBody), token)
+}
@@ -1,5 +1,9 @@
 package anydesk
 
+import (
+	"fmt"
+)
+
 // APINotFoundError will be thrown when a API request could not find any specifc data
 type APINotFoundError struct {
 }
@@ -32,3 +36,17 @@ func (e *APIBadCredentialsError) Error() string {
 
 	return "bad credentials"
 }
+
+// APIResponseTooLargeError will be returned when a response body exceeds API.MaxResponseSize.
+type APIResponseTooLargeError struct {
+	// The configured maximum size in bytes.
+	Limit int64
+}
+
+func (e *APIResponseTooLargeError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("response exceeds the maximum size of %d bytes", e.Limit)
+}
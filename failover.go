@@ -0,0 +1,138 @@
+package anydesk
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultProbeInterval is the interval in which an unhealthy primary endpoint is probed.
+const DefaultProbeInterval = 30 * time.Second
+
+// EndpointStatus contains the health of a single API endpoint.
+type EndpointStatus struct {
+	Endpoint string
+
+	// Is "false" after a connection error or 5xx response, until the endpoint answered successfully again.
+	Healthy bool
+
+	// Time of the first failure since the endpoint was last healthy.
+	DownSince time.Time
+}
+
+// endpointHealth tracks failed endpoints of an API.
+type endpointHealth struct {
+	mu     sync.Mutex
+	down   map[string]time.Time
+	probed time.Time
+}
+
+// order returns the endpoints to attempt, healthy ones first, each group in configured order.
+func (h *endpointHealth) order(endpoints []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ordered := make([]string, 0, len(endpoints))
+	var unhealthy []string
+
+	for _, e := range endpoints {
+		if _, down := h.down[e]; down {
+			unhealthy = append(unhealthy, e)
+			continue
+		}
+
+		ordered = append(ordered, e)
+	}
+
+	return append(ordered, unhealthy...)
+}
+
+func (h *endpointHealth) markDown(endpoint string, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.down == nil {
+		h.down = map[string]time.Time{}
+	}
+
+	if _, ok := h.down[endpoint]; !ok {
+		h.down[endpoint] = now
+		h.probed = now
+	}
+}
+
+func (h *endpointHealth) markUp(endpoint string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.down, endpoint)
+}
+
+// probeDue reports whether the unhealthy endpoint should be probed now.
+// Only a single caller per interval gets "true".
+func (h *endpointHealth) probeDue(endpoint string, now time.Time, interval time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, down := h.down[endpoint]; !down || now.Sub(h.probed) < interval {
+		return false
+	}
+
+	h.probed = now
+
+	return true
+}
+
+func (h *endpointHealth) status(endpoints []string) []EndpointStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := make([]EndpointStatus, len(endpoints))
+
+	for i, e := range endpoints {
+		since, down := h.down[e]
+		list[i] = EndpointStatus{Endpoint: e, Healthy: !down, DownSince: since}
+	}
+
+	return list
+}
+
+// endpoints returns the configured endpoints, the first one is the primary.
+func (api *API) endpoints() []string {
+	if len(api.Endpoints) > 0 {
+		return api.Endpoints
+	}
+
+	return []string{api.APIEndpoint}
+}
+
+// EndpointStatus returns the health of all configured endpoints, primary first.
+func (api *API) EndpointStatus() []EndpointStatus {
+	return api.internal().health.status(api.endpoints())
+}
+
+// probePrimary checks an unhealthy primary endpoint via "/auth" once per ProbeInterval,
+// so requests return to it as soon as it recovered.
+func (api *API) probePrimary() {
+	endpoints := api.endpoints()
+	if len(endpoints) < 2 {
+		return
+	}
+
+	interval := api.ProbeInterval
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+
+	if !api.internal().health.probeDue(endpoints[0], api.now(), interval) {
+		return
+	}
+
+	probe := NewAuthenticationRequest()
+	if err := api.prepare(probe); err != nil {
+		return
+	}
+
+	if _, _, err := api.send(probe, endpoints[0], &ResponseMeta{}); err == nil {
+		api.internal().health.markUp(endpoints[0])
+	}
+}
@@ -0,0 +1,195 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFailoverTestServer starts a server answering "/auth" with the given status code,
+// verifying the signature of every request.
+func newFailoverTestServer(t *testing.T, status *int32, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		_, err := VerifyRequest(req, testSecretLookup)
+		assert.NoError(t, err)
+
+		rw.WriteHeader(int(atomic.LoadInt32(status)))
+
+		data, err := ioutil.ReadFile("./_tests/auth_response.json")
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+}
+
+func TestAPI_Do_Failover(t *testing.T) {
+	var primaryStatus, primaryCalls, secondaryStatus, secondaryCalls int32 = 503, 0, 200, 0
+
+	primary := newFailoverTestServer(t, &primaryStatus, &primaryCalls)
+	defer primary.Close()
+
+	secondary := newFailoverTestServer(t, &secondaryStatus, &secondaryCalls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+	api.ProbeInterval = time.Hour
+
+	resp, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST_LICENSE", resp.LicenseID)
+	assert.Equal(t, int32(1), primaryCalls)
+	assert.Equal(t, int32(1), secondaryCalls)
+
+	status := api.EndpointStatus()
+	assert.False(t, status[0].Healthy)
+	assert.False(t, status[0].DownSince.IsZero())
+	assert.True(t, status[1].Healthy)
+
+	// the unhealthy primary is skipped until it was probed successfully
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), primaryCalls)
+	assert.Equal(t, int32(2), secondaryCalls)
+
+	// the primary is probed once the interval passed and used again after recovery
+	atomic.StoreInt32(&primaryStatus, 200)
+	api.ProbeInterval = time.Nanosecond
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), primaryCalls, "expected probe and request")
+	assert.Equal(t, int32(2), secondaryCalls)
+	assert.True(t, api.EndpointStatus()[0].Healthy)
+}
+
+func TestAPI_Do_FailoverConnectionError(t *testing.T) {
+	var status, calls int32 = 200, 0
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	secondary := newFailoverTestServer(t, &status, &calls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{down.URL, secondary.URL}
+
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls)
+	assert.False(t, api.EndpointStatus()[0].Healthy)
+}
+
+func TestAPI_Do_FailoverClientError(t *testing.T) {
+	var primaryStatus, primaryCalls, secondaryStatus, secondaryCalls int32 = 404, 0, 200, 0
+
+	primary := newFailoverTestServer(t, &primaryStatus, &primaryCalls)
+	defer primary.Close()
+
+	secondary := newFailoverTestServer(t, &secondaryStatus, &secondaryCalls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+
+	// 4xx are answers of a healthy endpoint and are not retried
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.IsType(t, &APINotFoundError{}, err)
+	assert.Equal(t, int32(0), secondaryCalls)
+	assert.True(t, api.EndpointStatus()[0].Healthy)
+}
+
+// headerRequest adds a header to the http request, overriding GetHTTPRequest.
+type headerRequest struct {
+	*AuthenticationRequest
+}
+
+func (r *headerRequest) GetHTTPRequest(api *API) (*http.Request, error) {
+	req, err := r.AuthenticationRequest.GetHTTPRequest(api)
+	if err == nil {
+		req.Header.Set("X-Custom", "yes")
+	}
+
+	return req, err
+}
+
+func TestAPI_Do_FailoverCustomRequest(t *testing.T) {
+	var hosts []string
+
+	handler := func(status int) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "yes", req.Header.Get("X-Custom"))
+			hosts = append(hosts, req.Host)
+			rw.WriteHeader(status)
+		})
+	}
+
+	primary := httptest.NewServer(handler(http.StatusBadGateway))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(handler(http.StatusOK))
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+
+	_, err := api.Do(&headerRequest{NewAuthenticationRequest()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{primary.Listener.Addr().String(), secondary.Listener.Addr().String()}, hosts)
+}
+
+func TestAPI_Do_LocalError(t *testing.T) {
+	var status, calls int32 = 200, 0
+
+	server := newFailoverTestServer(t, &status, &calls)
+	defer server.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.APIEndpoint = server.URL
+	api.CircuitBreaker = NewCircuitBreaker(2, time.Hour)
+	api.TLS = &TLSOptions{CAFile: "./_tests/missing.pem"}
+
+	api.internal().health.markDown(server.URL, time.Now())
+	api.CircuitBreaker.record(false, time.Now())
+
+	// configuration errors neither change the endpoint health nor count as breaker success
+	for i := 0; i < 3; i++ {
+		_, err := NewAuthenticationRequest().Do(api)
+		assert.IsType(t, &TLSConfigError{}, err)
+	}
+
+	assert.Equal(t, int32(0), calls)
+	assert.False(t, api.EndpointStatus()[0].Healthy)
+
+	api.CircuitBreaker.record(false, time.Now())
+	assert.Equal(t, CircuitOpen, api.CircuitBreaker.State())
+}
+
+func TestAPI_Do_FailoverAllDown(t *testing.T) {
+	var status, calls int32 = 502, 0
+
+	primary := newFailoverTestServer(t, &status, &calls)
+	defer primary.Close()
+
+	secondary := newFailoverTestServer(t, &status, &calls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.EqualError(t, err, "502 Bad Gateway")
+	assert.Equal(t, int32(2), calls)
+
+	// unhealthy endpoints are still attempted as last resort
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), calls)
+}
@@ -0,0 +1,239 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// column describes a single selectable field of a rendered type.
+type column struct {
+	// Field name as used by the AnyDesk API where possible, i.e. "client-version".
+	name string
+
+	// Returns the raw value of the field for the given row.
+	value func(row interface{}) interface{}
+}
+
+// limit is a license limit, where negative values represent no limit.
+type limit struct {
+	value    int64
+	duration bool
+}
+
+var clientColumns = []column{
+	{"cid", func(row interface{}) interface{} { return row.(*anydesk.ClientNode).ClientID }},
+	{"alias", func(row interface{}) interface{} { return row.(*anydesk.ClientNode).Alias }},
+	{"client-version", func(row interface{}) interface{} { return row.(*anydesk.ClientNode).ClientVersion }},
+	{"online", func(row interface{}) interface{} { return row.(*anydesk.ClientNode).Online }},
+	{"online-time", func(row interface{}) interface{} {
+		c := row.(*anydesk.ClientNode)
+		if !c.Online {
+			return nil
+		}
+
+		return time.Duration(c.OnlineSinceSeconds) * time.Second
+	}},
+	{"online-since", func(row interface{}) interface{} {
+		c := row.(*anydesk.ClientNode)
+		if !c.Online {
+			return nil
+		}
+
+		return c.OnlineSince()
+	}},
+	{"comment", func(row interface{}) interface{} { return row.(*anydesk.ClientNode).Comment }},
+	{"user-ref", func(row interface{}) interface{} { return userRef(row.(*anydesk.ClientNode).UserRef) }},
+}
+
+var sessionColumns = []column{
+	{"sid", func(row interface{}) interface{} { return row.(*anydesk.SessionNode).SessionID }},
+	{"active", func(row interface{}) interface{} { return row.(*anydesk.SessionNode).Active }},
+	{"from", func(row interface{}) interface{} { return slimID(row.(*anydesk.SessionNode).Source) }},
+	{"from-alias", func(row interface{}) interface{} { return slimAlias(row.(*anydesk.SessionNode).Source) }},
+	{"to", func(row interface{}) interface{} { return slimID(row.(*anydesk.SessionNode).Target) }},
+	{"to-alias", func(row interface{}) interface{} { return slimAlias(row.(*anydesk.SessionNode).Target) }},
+	{"start-time", func(row interface{}) interface{} { return row.(*anydesk.SessionNode).StartTime() }},
+	{"end-time", func(row interface{}) interface{} {
+		n := row.(*anydesk.SessionNode)
+		if n.Active || n.EndTimestamp == 0 {
+			return nil
+		}
+
+		return n.EndTime()
+	}},
+	{"duration", func(row interface{}) interface{} { return row.(*anydesk.SessionNode).Duration() }},
+	{"comment", func(row interface{}) interface{} { return row.(*anydesk.SessionNode).Comment }},
+	{"from-user-ref", func(row interface{}) interface{} { return userRef(row.(*anydesk.SessionNode).SourceUserRef) }},
+	{"to-user-ref", func(row interface{}) interface{} { return userRef(row.(*anydesk.SessionNode).TargetUserRef) }},
+}
+
+var sysinfoColumns = []column{
+	{"name", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Name }},
+	{"api-ver", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).APIVersion }},
+	{"license-name", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).License.Name }},
+	{"license-id", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).License.ID }},
+	{"expires", func(row interface{}) interface{} {
+		return row.(*anydesk.SysinfoResponse).License.ExpiresAt()
+	}},
+	{"has-expired", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).License.HasExpired }},
+	{"max-clients", func(row interface{}) interface{} {
+		return limit{value: int64(row.(*anydesk.SysinfoResponse).License.MaxClients)}
+	}},
+	{"max-sessions", func(row interface{}) interface{} {
+		return limit{value: int64(row.(*anydesk.SysinfoResponse).License.MaxSessions)}
+	}},
+	{"max-session-time", func(row interface{}) interface{} {
+		return limit{value: int64(row.(*anydesk.SysinfoResponse).License.MaxSessionTime), duration: true}
+	}},
+	{"namespaces", func(row interface{}) interface{} {
+		var list []string
+		for _, n := range row.(*anydesk.SysinfoResponse).License.Namespaces {
+			list = append(list, fmt.Sprintf("%s (%d)", n.Name, n.Size))
+		}
+
+		return strings.Join(list, ", ")
+	}},
+	{"clients-total", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Clients.Total }},
+	{"clients-online", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Clients.Online }},
+	{"sessions-total", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Sessions.Total }},
+	{"sessions-active", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Sessions.Active }},
+	{"standalone", func(row interface{}) interface{} { return row.(*anydesk.SysinfoResponse).Standalone }},
+}
+
+var authenticationColumns = []column{
+	{"result", func(row interface{}) interface{} { return row.(*anydesk.AuthenticationResponse).Result }},
+	{"license-id", func(row interface{}) interface{} { return row.(*anydesk.AuthenticationResponse).LicenseID }},
+	{"code", func(row interface{}) interface{} { return row.(*anydesk.AuthenticationResponse).Code }},
+	{"error", func(row interface{}) interface{} { return row.(*anydesk.AuthenticationResponse).Error }},
+}
+
+// ClientFields returns the names of all fields available for clients.
+func ClientFields() []string {
+	return columnNames(clientColumns)
+}
+
+// SessionFields returns the names of all fields available for sessions.
+func SessionFields() []string {
+	return columnNames(sessionColumns)
+}
+
+// SysinfoFields returns the names of all fields available for system information.
+func SysinfoFields() []string {
+	return columnNames(sysinfoColumns)
+}
+
+func columnNames(cols []column) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+// selectColumns returns the columns of the given field names in the given order.
+// Without fields all columns are returned.
+func selectColumns(all []column, fields []string) ([]column, error) {
+	if len(fields) == 0 {
+		return all, nil
+	}
+
+	cols := make([]column, 0, len(fields))
+
+	for _, f := range fields {
+		found := false
+
+		for _, c := range all {
+			if c.name == f {
+				cols = append(cols, c)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, &UnknownFieldError{Field: f, Available: columnNames(all)}
+		}
+	}
+
+	return cols, nil
+}
+
+func slimID(n *anydesk.ClientSlimNode) interface{} {
+	if n == nil {
+		return nil
+	}
+
+	return n.ClientID
+}
+
+func slimAlias(n *anydesk.ClientSlimNode) interface{} {
+	if n == nil {
+		return nil
+	}
+
+	return n.Alias
+}
+
+func userRef(u *anydesk.UserRef) interface{} {
+	if u == nil {
+		return nil
+	}
+
+	return u.String()
+}
+
+// orderedRecord is a JSON object that keeps the order of the selected fields.
+type orderedRecord struct {
+	keys   []string
+	values []interface{}
+}
+
+func record(cols []column, row interface{}) *orderedRecord {
+	r := &orderedRecord{
+		keys:   make([]string, len(cols)),
+		values: make([]interface{}, len(cols)),
+	}
+
+	for i, c := range cols {
+		r.keys[i] = c.name
+		r.values[i] = jsonValue(c.value(row))
+	}
+
+	return r
+}
+
+// MarshalJSON encodes the record as object with the fields in selected order.
+func (r *orderedRecord) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, k := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,250 @@
+// Package format renders AnyDesk API results as aligned tables, JSON, NDJSON or CSV.
+//
+//   r := &format.Renderer{
+//       Format: format.Table,
+//       Fields: []string{"cid", "alias", "online"},
+//   }
+//
+//   err := r.Clients(os.Stdout, response.List)
+//
+// Tables use human friendly durations and times, all other formats use RFC 3339
+// times and durations in seconds, so they can be processed by scripts.
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+)
+
+// Format defines the output format of a Renderer.
+type Format string
+
+const (
+	// Table renders aligned columns for humans.
+	Table Format = "table"
+
+	// JSON renders a pretty printed JSON array.
+	JSON Format = "json"
+
+	// NDJSON renders one JSON object per line.
+	NDJSON Format = "ndjson"
+
+	// CSV renders comma separated values with a header line.
+	CSV Format = "csv"
+)
+
+// Formats contains all supported formats.
+var Formats = []Format{Table, JSON, NDJSON, CSV}
+
+// ParseFormat returns the format of the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown format %q", name)
+}
+
+// ParseFields splits a comma separated field list, i.e. "cid,alias,online".
+func ParseFields(list string) []string {
+	var fields []string
+
+	for _, f := range strings.Split(list, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// UnknownFieldError will be returned when a selected field does not exist for the rendered type.
+type UnknownFieldError struct {
+	Field     string
+	Available []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("unknown field %q, available: %s", e.Field, strings.Join(e.Available, ","))
+}
+
+// Renderer writes results in the configured format.
+type Renderer struct {
+	// Output format, defaults to Table.
+	Format Format
+
+	// Selected and ordered fields, defaults to all fields of the rendered type.
+	Fields []string
+
+	// Location used for human friendly times, defaults to time.Local.
+	Location *time.Location
+}
+
+// NewRenderer returns a renderer for the given format and selected fields.
+func NewRenderer(format Format, fields []string) *Renderer {
+	return &Renderer{
+		Format: format,
+		Fields: fields,
+	}
+}
+
+// Clients renders the given client list.
+func (r *Renderer) Clients(w io.Writer, list []anydesk.ClientNode) error {
+	rows := make([]interface{}, len(list))
+	for i := range list {
+		rows[i] = &list[i]
+	}
+
+	return r.render(w, clientColumns, rows, false)
+}
+
+// Sessions renders the given session list.
+func (r *Renderer) Sessions(w io.Writer, list []anydesk.SessionNode) error {
+	rows := make([]interface{}, len(list))
+	for i := range list {
+		rows[i] = &list[i]
+	}
+
+	return r.render(w, sessionColumns, rows, false)
+}
+
+// Sysinfo renders the given system information. Tables are rendered vertically.
+func (r *Renderer) Sysinfo(w io.Writer, info *anydesk.SysinfoResponse) error {
+	return r.render(w, sysinfoColumns, []interface{}{info}, true)
+}
+
+// Authentication renders the given authentication result. Tables are rendered vertically.
+func (r *Renderer) Authentication(w io.Writer, resp *anydesk.AuthenticationResponse) error {
+	return r.render(w, authenticationColumns, []interface{}{resp}, true)
+}
+
+func (r *Renderer) render(w io.Writer, all []column, rows []interface{}, vertical bool) error {
+	cols, err := selectColumns(all, r.Fields)
+	if err != nil {
+		return err
+	}
+
+	switch r.Format {
+	case JSON:
+		return r.renderJSON(w, cols, rows, vertical)
+	case NDJSON:
+		return r.renderNDJSON(w, cols, rows)
+	case CSV:
+		return r.renderCSV(w, cols, rows)
+	case Table, "":
+		if vertical {
+			return r.renderVerticalTable(w, cols, rows)
+		}
+
+		return r.renderTable(w, cols, rows)
+	default:
+		return fmt.Errorf("unknown format %q", r.Format)
+	}
+}
+
+func (r *Renderer) renderTable(w io.Writer, cols []column, rows []interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = strings.ToUpper(c.name)
+	}
+
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		values := make([]string, len(cols))
+		for i, c := range cols {
+			values[i] = r.human(c.value(row))
+		}
+
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func (r *Renderer) renderVerticalTable(w io.Writer, cols []column, rows []interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for i, row := range rows {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		for _, c := range cols {
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(c.name), r.human(c.value(row)))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (r *Renderer) renderJSON(w io.Writer, cols []column, rows []interface{}, single bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if single && len(rows) == 1 {
+		return enc.Encode(record(cols, rows[0]))
+	}
+
+	list := make([]*orderedRecord, len(rows))
+	for i, row := range rows {
+		list[i] = record(cols, row)
+	}
+
+	return enc.Encode(list)
+}
+
+func (r *Renderer) renderNDJSON(w io.Writer, cols []column, rows []interface{}) error {
+	enc := json.NewEncoder(w)
+
+	for _, row := range rows {
+		if err := enc.Encode(record(cols, row)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Renderer) renderCSV(w io.Writer, cols []column, rows []interface{}) error {
+	cw := csv.NewWriter(w)
+
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.name
+	}
+
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		values := make([]string, len(cols))
+		for i, c := range cols {
+			values[i] = machine(c.value(row))
+		}
+
+		if err := cw.Write(values); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
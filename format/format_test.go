@@ -0,0 +1,146 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/adrianrudnik/anydesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFixture(t *testing.T, path string, v interface{}) {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, v))
+}
+
+func testSessions(t *testing.T) []anydesk.SessionNode {
+	resp := &anydesk.SessionListResponse{}
+	loadFixture(t, "../_tests/session_list.json", resp)
+
+	return resp.List
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("NDJSON")
+	assert.NoError(t, err)
+	assert.Equal(t, NDJSON, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestParseFields(t *testing.T) {
+	assert.Equal(t, []string{"cid", "alias", "online"}, ParseFields(" cid, alias,,online "))
+	assert.Nil(t, ParseFields(""))
+}
+
+func TestRenderer_SessionsTable(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	r := &Renderer{Format: Table, Fields: []string{"sid", "from-alias", "start-time", "duration", "comment"}, Location: time.UTC}
+	assert.NoError(t, r.Sessions(out, testSessions(t)))
+
+	assert.Equal(t, ""+
+		"SID       FROM-ALIAS   START-TIME           DURATION  COMMENT\n"+
+		"SESSION1  TEST_ALIAS1  2020-05-26 14:50:26  1m        TEST-COMMENT1\n"+
+		"SESSION2  -            2020-05-26 14:52:06  30s       -\n",
+		out.String())
+}
+
+func TestRenderer_SessionsCSV(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	r := NewRenderer(CSV, []string{"sid", "to", "end-time", "duration", "active"})
+	assert.NoError(t, r.Sessions(out, testSessions(t)))
+
+	assert.Equal(t, ""+
+		"sid,to,end-time,duration,active\n"+
+		"SESSION1,100000001,2020-05-26T14:51:26Z,60,false\n"+
+		"SESSION2,100000000,,30,true\n",
+		out.String())
+}
+
+func TestRenderer_ClientsNDJSON(t *testing.T) {
+	resp := &anydesk.ClientListResponse{}
+	loadFixture(t, "../_tests/client_list_all.json", resp)
+
+	out := &bytes.Buffer{}
+
+	r := NewRenderer(NDJSON, []string{"online", "cid", "alias", "online-time"})
+	assert.NoError(t, r.Clients(out, resp.List[:2]))
+
+	assert.Equal(t, ""+
+		`{"online":true,"cid":121,"alias":"","online-time":49215}`+"\n"+
+		`{"online":true,"cid":122,"alias":"","online-time":49215}`+"\n",
+		out.String())
+}
+
+func TestRenderer_ClientsJSON(t *testing.T) {
+	resp := &anydesk.ClientListResponse{}
+	loadFixture(t, "../_tests/client_list_all.json", resp)
+
+	out := &bytes.Buffer{}
+
+	r := NewRenderer(JSON, nil)
+	assert.NoError(t, r.Clients(out, resp.List))
+
+	var list []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &list))
+	assert.Len(t, list, 8)
+	assert.Len(t, list[0], len(ClientFields()))
+	assert.Equal(t, "demo@ad", list[7]["alias"])
+	assert.Nil(t, list[7]["online-since"])
+}
+
+func TestRenderer_SysinfoTable(t *testing.T) {
+	info := &anydesk.SysinfoResponse{}
+	loadFixture(t, "../_tests/sysinfo.json", info)
+
+	out := &bytes.Buffer{}
+
+	r := &Renderer{Fields: []string{"license-name", "expires", "max-sessions", "max-clients", "namespaces"}, Location: time.UTC}
+	assert.NoError(t, r.Sysinfo(out, info))
+
+	assert.Equal(t, ""+
+		"LICENSE-NAME  TEST_LICENSE_NAME\n"+
+		"EXPIRES       2021-06-17 09:06:59\n"+
+		"MAX-SESSIONS  4\n"+
+		"MAX-CLIENTS   unlimited\n"+
+		"NAMESPACES    demo1 (20), demo2 (1)\n",
+		out.String())
+}
+
+func TestRenderer_SysinfoJSON(t *testing.T) {
+	info := &anydesk.SysinfoResponse{}
+	loadFixture(t, "../_tests/sysinfo.json", info)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, NewRenderer(JSON, []string{"api-ver", "sessions-active"}).Sysinfo(out, info))
+	assert.JSONEq(t, `{"api-ver":"1.1","sessions-active":2}`, out.String())
+	assert.NotContains(t, out.String(), "TEST_APIPASS")
+}
+
+func TestRenderer_UnknownField(t *testing.T) {
+	err := NewRenderer(Table, []string{"cid", "nope"}).Clients(ioutil.Discard, nil)
+
+	assert.IsType(t, &UnknownFieldError{}, err)
+	assert.Equal(t, "nope", err.(*UnknownFieldError).Field)
+}
+
+func ExampleRenderer_Clients() {
+	list := []anydesk.ClientNode{
+		{ClientID: 123456789, Alias: "demo@ad", Online: false},
+	}
+
+	r := NewRenderer(CSV, ParseFields("cid,alias,online"))
+	_ = r.Clients(os.Stdout, list)
+
+	// Output:
+	// cid,alias,online
+	// 123456789,demo@ad,false
+}
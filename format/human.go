@@ -0,0 +1,118 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanDuration returns a short human friendly representation of the duration, i.e. "2d 3h".
+// Only the two most significant units are shown.
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + HumanDuration(-d)
+	}
+
+	if d < time.Second {
+		return "0s"
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var parts []string
+
+	for _, u := range units {
+		if d < u.size && len(parts) == 0 {
+			continue
+		}
+
+		n := d / u.size
+		d -= n * u.size
+
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+		}
+
+		if len(parts) == 2 || (len(parts) > 0 && n == 0) {
+			break
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// human formats the raw value of a column for tables.
+func (r *Renderer) human(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case bool:
+		if t {
+			return "yes"
+		}
+
+		return "no"
+	case time.Time:
+		loc := r.Location
+		if loc == nil {
+			loc = time.Local
+		}
+
+		return t.In(loc).Format("2006-01-02 15:04:05")
+	case time.Duration:
+		return HumanDuration(t)
+	case limit:
+		if t.value < 0 {
+			return "unlimited"
+		}
+
+		if t.duration {
+			return HumanDuration(time.Duration(t.value) * time.Second)
+		}
+
+		return strconv.FormatInt(t.value, 10)
+	case string:
+		if t == "" {
+			return "-"
+		}
+
+		return t
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// machine formats the raw value of a column for CSV.
+func machine(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case time.Time, time.Duration, limit:
+		return fmt.Sprint(jsonValue(t))
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// jsonValue converts the raw value of a column for JSON, times as RFC 3339 and durations in seconds.
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC().Format(time.RFC3339)
+	case time.Duration:
+		return int64(t / time.Second)
+	case limit:
+		return t.value
+	default:
+		return t
+	}
+}
@@ -0,0 +1,49 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                                  "0s",
+		500 * time.Millisecond:             "0s",
+		45 * time.Second:                   "45s",
+		3725 * time.Second:                 "1h 2m",
+		24*time.Hour + time.Minute:         "1d",
+		50*time.Hour + 10*time.Minute:      "2d 2h",
+		-90 * time.Second:                  "-1m 30s",
+		7*24*time.Hour + 3*time.Hour + 5e9: "7d 3h",
+	}
+
+	for d, expected := range cases {
+		assert.Equal(t, expected, HumanDuration(d), d.String())
+	}
+}
+
+func TestRenderer_Human(t *testing.T) {
+	r := &Renderer{Location: time.UTC}
+
+	a := assert.New(t)
+	a.Equal("-", r.human(nil))
+	a.Equal("-", r.human(""))
+	a.Equal("yes", r.human(true))
+	a.Equal("no", r.human(false))
+	a.Equal("2020-05-26 14:50:26", r.human(time.Unix(1590504626, 0)))
+	a.Equal("unlimited", r.human(limit{value: -1}))
+	a.Equal("20", r.human(limit{value: 20}))
+	a.Equal("1h", r.human(limit{value: 3600, duration: true}))
+	a.Equal("42", r.human(int64(42)))
+}
+
+func TestMachine(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("", machine(nil))
+	a.Equal("2020-05-26T14:50:26Z", machine(time.Unix(1590504626, 0)))
+	a.Equal("90", machine(90*time.Second))
+	a.Equal("-1", machine(limit{value: -1}))
+	a.Equal("true", machine(true))
+}
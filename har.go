@@ -0,0 +1,273 @@
+package anydesk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianrudnik/anydesk/internal/redact"
+)
+
+// HARRecorder collects http exchanges and writes them as HAR 1.2 file, which can be inspected
+// with browser tooling or attached to support tickets. Assign it to API.HAR to record every
+// exchange, including failover attempts, or add the DebugInfo of single requests.
+//
+// The request signature and the secrets of "/sysinfo" responses are redacted.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder returns an empty recorder.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// Add records the exchange of the given debug information, see SetDebug.
+func (r *HARRecorder) Add(d *DebugInfo) {
+	r.add(d, nil)
+}
+
+// add records the exchange, err is the error of a failed exchange.
+func (r *HARRecorder) add(d *DebugInfo, err error) {
+	if d == nil || d.Request == nil {
+		return
+	}
+
+	e := newHAREntry(d, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, e)
+}
+
+// Len returns the number of recorded exchanges.
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// Reset removes all recorded exchanges.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// WriteTo writes the recorded exchanges as HAR 1.2 document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	entries := append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(harDocument{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "github.com/adrianrudnik/anydesk", Version: "1"},
+			Entries: entries,
+		},
+	}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+
+	return int64(n), err
+}
+
+// WriteFile writes the recorded exchanges as HAR 1.2 file.
+func (r *HARRecorder) WriteFile(path string) error {
+	buf := &bytes.Buffer{}
+
+	if _, err := r.WriteTo(buf); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// HAR 1.2 document, see http://www.softwareishard.com/blog/har-12-spec/
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harTimings in milliseconds, -1 for phases that did not happen.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+func newHAREntry(d *DebugInfo, err error) harEntry {
+	req := d.Request
+	t := d.Timings
+
+	e := harEntry{
+		StartedDateTime: t.Started.Format(time.RFC3339Nano),
+		Time:            milliseconds(t.Total),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(d.RequestBody),
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{
+			Blocked: -1,
+			DNS:     optionalMilliseconds(t.DNS),
+			// The connect time includes the TLS handshake
+			Connect: optionalMilliseconds(t.Connect + t.TLS),
+			Send:    milliseconds(t.Send),
+			Wait:    milliseconds(t.Wait),
+			Receive: milliseconds(t.Receive),
+			SSL:     optionalMilliseconds(t.TLS),
+		},
+	}
+
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			e.Request.QueryString = append(e.Request.QueryString, harNameValue{Name: name, Value: v})
+		}
+	}
+
+	if len(d.RequestBody) > 0 {
+		e.Request.PostData = &harPostData{MimeType: "application/json", Text: string(redact.JSON(d.RequestBody, redact.Fields))}
+	}
+
+	if err != nil {
+		e.Comment = err.Error()
+	}
+
+	resp := d.Response
+	if resp == nil {
+		return e
+	}
+
+	e.Response.Status = resp.StatusCode
+	e.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)))
+	e.Response.HTTPVersion = resp.Proto
+	e.Response.Headers = harHeaders(resp.Header)
+	e.Response.Content.MimeType = resp.Header.Get("Content-Type")
+
+	if d.ResponseBody != nil {
+		body := redact.JSON(d.ResponseBody, redact.Fields)
+
+		e.Response.BodySize = len(d.ResponseBody)
+		e.Response.Content.Size = len(body)
+		e.Response.Content.Text = string(body)
+	}
+
+	return e
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	list := []harNameValue{}
+
+	for name, values := range h {
+		for _, v := range values {
+			list = append(list, harNameValue{Name: name, Value: v})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func optionalMilliseconds(d time.Duration) float64 {
+	if d == 0 {
+		return -1
+	}
+
+	return milliseconds(d)
+}
@@ -0,0 +1,149 @@
+package anydesk
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testHAR struct {
+	Log struct {
+		Version string
+		Entries []struct {
+			StartedDateTime string
+			Time            float64
+			Request         struct {
+				Method      string
+				URL         string
+				Headers     []harNameValue
+				QueryString []harNameValue
+				PostData    *harPostData
+			}
+			Response struct {
+				Status  int
+				Content harContent
+			}
+			Timings map[string]float64
+			Comment string
+		}
+	}
+}
+
+func TestHARRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/sysinfo" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := ioutil.ReadFile("./_tests/sysinfo.json")
+		assert.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write(data)
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "LICENSE", "PASSWORD")
+	api.HAR = NewHARRecorder()
+
+	_, err := NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+
+	err = NewSessionCommentChangeRequest("S1", "remote support").Do(api)
+	assert.IsType(t, &APINotFoundError{}, err)
+
+	assert.Equal(t, 2, api.HAR.Len())
+
+	buf := &bytes.Buffer{}
+	_, err = api.HAR.WriteTo(buf)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "TEST_APIPASS")
+	assert.NotContains(t, buf.String(), "TEST_LICENSE_KEY")
+
+	var har testHAR
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+
+	sysinfo := har.Log.Entries[0]
+	assert.Equal(t, "GET", sysinfo.Request.Method)
+	assert.Equal(t, server.URL+"/sysinfo", sysinfo.Request.URL)
+	assert.Equal(t, "Authorization", sysinfo.Request.Headers[0].Name)
+	assert.Regexp(t, `^AD LICENSE:\d+:\[REDACTED\]$`, sysinfo.Request.Headers[0].Value)
+	assert.Equal(t, 200, sysinfo.Response.Status)
+	assert.Equal(t, "application/json", sysinfo.Response.Content.MimeType)
+	assert.Contains(t, sysinfo.Response.Content.Text, `"api-password":"[REDACTED]"`)
+	assert.Contains(t, sysinfo.Response.Content.Text, `"AnyDesk REST"`)
+	assert.True(t, sysinfo.Time > 0)
+
+	for _, phase := range []string{"blocked", "dns", "connect", "send", "wait", "receive", "ssl"} {
+		assert.Contains(t, sysinfo.Timings, phase)
+	}
+
+	comment := har.Log.Entries[1]
+	assert.Equal(t, "PATCH", comment.Request.Method)
+	assert.Equal(t, `{"comment":"remote support"}`, comment.Request.PostData.Text)
+	assert.Equal(t, 404, comment.Response.Status)
+
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "anydesk.har")
+	assert.NoError(t, api.HAR.WriteFile(path))
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.String(), string(data))
+
+	api.HAR.Reset()
+	assert.Equal(t, 0, api.HAR.Len())
+}
+
+func TestHARRecorder_Add(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	server := NewAPITestServer(t, "/auth", "./_tests/auth_response.json", http.StatusOK)
+	defer server.Close()
+
+	req := NewAuthenticationRequest()
+	_, err := req.Do(NewAPITestClient(t, server, "", ""))
+	assert.NoError(t, err)
+
+	assert.False(t, req.GetDebug().Timings.Started.IsZero())
+	assert.True(t, req.GetDebug().Timings.Total > 0)
+
+	recorder := NewHARRecorder()
+	recorder.Add(req.GetDebug())
+	recorder.Add(&DebugInfo{})
+	assert.Equal(t, 1, recorder.Len())
+}
+
+func TestHARRecorder_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.HAR = NewHARRecorder()
+
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	_, err = api.HAR.WriteTo(buf)
+	assert.NoError(t, err)
+
+	var har testHAR
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &har))
+	assert.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, 0, har.Log.Entries[0].Response.Status)
+	assert.NotEmpty(t, har.Log.Entries[0].Comment)
+}
@@ -0,0 +1,69 @@
+// Package redact replaces secrets in the JSON bodies exchanged with the AnyDesk API.
+package redact
+
+import "encoding/json"
+
+// Redacted replaces the redacted secrets.
+const Redacted = "[REDACTED]"
+
+// Fields contains the JSON properties of API bodies that hold secrets, i.e. of "/sysinfo".
+var Fields = []string{"api-password", "license-key"}
+
+// JSON replaces the values of the given JSON properties in the body with Redacted, at any depth.
+// Bodies that are not valid JSON, or contain none of the properties, are returned untouched.
+func JSON(body []byte, fields []string) []byte {
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+
+	if !value(v, fields) {
+		return body
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+
+	return data
+}
+
+func value(v interface{}, fields []string) (changed bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if contains(fields, k) {
+				t[k] = Redacted
+				changed = true
+				continue
+			}
+
+			if value(child, fields) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if value(child, fields) {
+				changed = true
+			}
+		}
+	}
+
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,16 @@
+package redact
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(`{"a":{"license-key":"[REDACTED]"},"b":1}`, string(JSON([]byte(`{"a":{"license-key":"x"},"b":1}`), Fields)))
+	a.Equal(`[{"api-password":"[REDACTED]"}]`, string(JSON([]byte(`[{"api-password":"x"}]`), Fields)))
+	a.Equal(`{"alias":"[REDACTED]"}`, string(JSON([]byte(`{"alias":"x"}`), []string{"alias"})))
+	a.Equal(`{ "b": 1 }`, string(JSON([]byte(`{ "b": 1 }`), Fields)))
+	a.Equal(`{"license-key":"x"}`, string(JSON([]byte(`{"license-key":"x"}`), nil)))
+	a.Equal(`not json`, string(JSON([]byte(`not json`), Fields)))
+}
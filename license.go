@@ -0,0 +1,101 @@
+package anydesk
+
+import "time"
+
+// License contains the details of the AnyDesk license as returned by "/sysinfo".
+// Negative limits mean the license has no limit. Key and APIPassword are redacted when printed, logged or marshalled, see Reveal.
+type License struct {
+	Name             string      `json:"name" schema:"required"`
+	ExpiresTimestamp int64       `json:"expires" schema:"required"`
+	HasExpired       bool        `json:"has-expired" schema:"deprecated"` // undocumented or deprecated
+	MaxClients       int         `json:"max-clients" schema:"required"`
+	MaxSessions      int         `json:"max-sessions" schema:"required"`
+	MaxSessionTime   int         `json:"max-session-time"`
+	Namespaces       []Namespace `json:"namespaces"`
+	ID               string      `json:"license-id" schema:"required"`
+	Key              string      `json:"license-key"`
+	APIPassword      string      `json:"api-password"`
+	PowerUser        bool        `json:"power-user" schema:"deprecated"` // undocumented or deprecated
+
+	clock Clock
+}
+
+// ExpiresAt returns the time the license expires.
+func (l *License) ExpiresAt() time.Time {
+	return time.Unix(l.ExpiresTimestamp, 0)
+}
+
+// ExpiresIn returns the time left until the license expires, negative if it already expired.
+// Licenses returned by the API use its Clock, others the SystemClock.
+func (l *License) ExpiresIn() time.Duration {
+	return l.ExpiresAt().Sub(clockOrSystem(l.clock).Now())
+}
+
+// MaxSessionDuration returns the maximum duration of a session, zero if sessions are not limited.
+func (l *License) MaxSessionDuration() time.Duration {
+	if l.MaxSessionTime < 0 {
+		return 0
+	}
+
+	return time.Duration(l.MaxSessionTime) * time.Second
+}
+
+// NamespaceUsage returns the share of each namespace in the size of all namespaces, from 0 to 1.
+func (l *License) NamespaceUsage() map[string]float64 {
+	total := 0
+	for _, n := range l.Namespaces {
+		total += n.Size
+	}
+
+	usage := make(map[string]float64, len(l.Namespaces))
+
+	for _, n := range l.Namespaces {
+		usage[n.Name] = ratio(n.Size, total)
+	}
+
+	return usage
+}
+
+// Namespace is a namespace of the license.
+type Namespace struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// ClientStats contains the number of clients of the license.
+type ClientStats struct {
+	Total  int `json:"total"`
+	Online int `json:"online"`
+}
+
+// OnlineRatio returns the share of online clients, from 0 to 1.
+func (s *ClientStats) OnlineRatio() float64 {
+	return ratio(s.Online, s.Total)
+}
+
+// SessionStats contains the number of sessions of the license.
+type SessionStats struct {
+	Total  int `json:"total"`
+	Active int `json:"active"`
+}
+
+// ClientUtilization returns the number of clients relative to the client limit of the license,
+// above 1 if the limit is exceeded. Zero if clients are not limited.
+func (r *SysinfoResponse) ClientUtilization() float64 {
+	return ratio(r.Clients.Total, r.License.MaxClients)
+}
+
+// SessionUtilization returns the number of active sessions relative to the session limit of the license,
+// above 1 if the limit is exceeded. Zero if sessions are not limited.
+func (r *SysinfoResponse) SessionUtilization() float64 {
+	return ratio(r.Sessions.Active, r.License.MaxSessions)
+}
+
+// ratio returns value relative to max, zero for unlimited or empty maximums.
+func ratio(value int, max int) float64 {
+	if max <= 0 {
+		return 0
+	}
+
+	return float64(value) / float64(max)
+}
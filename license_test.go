@@ -0,0 +1,55 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLicense_Expiry(t *testing.T) {
+	expires := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	l := &License{ExpiresTimestamp: expires.Unix()}
+	assert.True(t, expires.Equal(l.ExpiresAt()))
+	assert.InDelta(t, float64(48*time.Hour), float64(l.ExpiresIn()), float64(time.Minute))
+
+	l.ExpiresTimestamp = time.Now().Add(-time.Hour).Unix()
+	assert.True(t, l.ExpiresIn() < 0)
+}
+
+func TestLicense_Limits(t *testing.T) {
+	l := &License{
+		MaxSessionTime: 3600,
+		Namespaces: []Namespace{
+			{Name: "demo1", Size: 30},
+			{Name: "demo2", Size: 10},
+		},
+	}
+
+	assert.Equal(t, time.Hour, l.MaxSessionDuration())
+	assert.Equal(t, map[string]float64{"demo1": 0.75, "demo2": 0.25}, l.NamespaceUsage())
+
+	l.MaxSessionTime = -1
+	assert.Equal(t, time.Duration(0), l.MaxSessionDuration())
+
+	l.Namespaces = []Namespace{{Name: "empty"}}
+	assert.Equal(t, map[string]float64{"empty": 0}, l.NamespaceUsage())
+}
+
+func TestSysinfoResponse_Utilization(t *testing.T) {
+	r := &SysinfoResponse{
+		License:  License{MaxClients: 200, MaxSessions: 4},
+		Clients:  ClientStats{Total: 50, Online: 10},
+		Sessions: SessionStats{Total: 599, Active: 3},
+	}
+
+	assert.Equal(t, 0.25, r.ClientUtilization())
+	assert.Equal(t, 0.75, r.SessionUtilization())
+	assert.Equal(t, 0.2, r.Clients.OnlineRatio())
+
+	// negative limits are unlimited
+	r.License.MaxClients = -1
+	r.License.MaxSessions = -1
+	assert.Equal(t, float64(0), r.ClientUtilization())
+	assert.Equal(t, float64(0), r.SessionUtilization())
+}
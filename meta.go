@@ -0,0 +1,53 @@
+package anydesk
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMeta describes the http exchange that produced a response.
+type ResponseMeta struct {
+	// http status code of the response.
+	StatusCode int
+
+	// http headers of the response.
+	Header http.Header
+
+	// Duration of the whole request, including rate limiting and failover attempts.
+	Latency time.Duration
+
+	// Number of http requests sent, more than one after failover. Zero when served from the cache.
+	Attempts int
+
+	// Endpoint that answered the request.
+	Endpoint string
+
+	// Time the response was received from the API. Cached responses keep their original time.
+	FetchedAt time.Time
+
+	// Indicates whether the response was served from the API.Cache.
+	CacheStatus CacheStatus
+
+	// Differences between the response and the known schema, only collected with API.StrictDecoding.
+	Drift []SchemaDrift
+}
+
+// clone returns a copy that does not share the headers.
+func (m ResponseMeta) clone() ResponseMeta {
+	m.Header = m.Header.Clone()
+	return m
+}
+
+// Meta returns the metadata of the last execution of the request, nil before.
+func (r *BaseRequest) Meta() *ResponseMeta {
+	return r.meta
+}
+
+// CacheStatus returns whether the response of the last execution was served from the API.Cache.
+func (r *BaseRequest) CacheStatus() CacheStatus {
+	if r.meta == nil {
+		return CacheNone
+	}
+
+	return r.meta.CacheStatus
+}
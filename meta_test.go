@@ -0,0 +1,92 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseMeta(t *testing.T) {
+	clock := newTestClock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// the API takes its time to answer
+		clock.Add(5 * time.Millisecond)
+
+		rw.Header().Set("X-Request-Id", "TEST_REQUEST")
+		_, _ = rw.Write([]byte(`{"cid": 100000000, "online": true, "online-time": 3600}`))
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.Cache = NewResponseCache(time.Minute)
+	api.Clock = clock
+
+	before := clock.Now()
+
+	resp, err := NewClientDetailRequest(100000000).Do(api)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.Meta.StatusCode)
+	assert.Equal(t, "TEST_REQUEST", resp.Meta.Header.Get("X-Request-Id"))
+	assert.Equal(t, 1, resp.Meta.Attempts)
+	assert.Equal(t, server.URL, resp.Meta.Endpoint)
+	assert.Equal(t, CacheMiss, resp.Meta.CacheStatus)
+	assert.Equal(t, 5*time.Millisecond, resp.Meta.Latency)
+	assert.Equal(t, before.Add(5*time.Millisecond), resp.Meta.FetchedAt)
+
+	// online since is relative to the fetch time, not to the time of the call
+	assert.Equal(t, resp.Meta.FetchedAt.Add(-time.Hour), resp.OnlineSince())
+
+	// cached responses keep the original fetch time
+	clock.Add(time.Second)
+
+	cached, err := NewClientDetailRequest(100000000).Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheHit, cached.Meta.CacheStatus)
+	assert.Equal(t, 0, cached.Meta.Attempts)
+	assert.Equal(t, resp.Meta.FetchedAt, cached.Meta.FetchedAt)
+	assert.Equal(t, resp.OnlineSince(), cached.OnlineSince())
+}
+
+func TestResponseMeta_List(t *testing.T) {
+	server := NewAPITestServer(t, "/clients?limit=-1&offset=0&order=desc", "./_tests/client_list_all.json", http.StatusOK)
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	resp, err := NewClientListRequest(nil).Do(api)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.Meta)
+
+	for _, c := range resp.List {
+		assert.Equal(t, resp.Meta.FetchedAt.Add(-time.Duration(c.OnlineSinceSeconds)*time.Second), c.OnlineSince())
+	}
+}
+
+func TestResponseMeta_Failover(t *testing.T) {
+	var primaryStatus, secondaryStatus, calls int32 = 500, 200, 0
+
+	primary := newFailoverTestServer(t, &primaryStatus, &calls)
+	defer primary.Close()
+
+	secondary := newFailoverTestServer(t, &secondaryStatus, &calls)
+	defer secondary.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.Endpoints = []string{primary.URL, secondary.URL}
+
+	resp, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Meta.Attempts)
+	assert.Equal(t, secondary.URL, resp.Meta.Endpoint)
+}
+
+func TestClientNode_OnlineSince(t *testing.T) {
+	n := &ClientNode{OnlineSinceSeconds: 60}
+
+	// nodes not fetched from the API are relative to now
+	assert.WithinDuration(t, time.Now().Add(-time.Minute), n.OnlineSince(), time.Second)
+}
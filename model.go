@@ -0,0 +1,185 @@
+package anydesk
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UserRef references the user a client is assigned to, as configured in the AnyDesk client.
+type UserRef string
+
+// UnmarshalJSON accepts a string. Other values are kept as their raw JSON text,
+// so a change of the format does not break decoding.
+func (u *UserRef) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*u = UserRef(s)
+		return nil
+	}
+
+	*u = UserRef(bytes.TrimSpace(data))
+
+	return nil
+}
+
+// String returns the reference, or an empty string for nil.
+func (u *UserRef) String() string {
+	if u == nil {
+		return ""
+	}
+
+	return string(*u)
+}
+
+// modelFields contains the json keys mapped by the fields of a model type.
+var modelFields sync.Map
+
+// presence reports whether a field of a model was present and not null.
+type presence struct {
+	key string
+	set *bool
+}
+
+// decodeModel decodes the json object into v, which must be a pointer to an alias type of a model
+// without its own UnmarshalJSON. The object is decoded once into its fields, which are assigned to v
+// or returned as extra if v does not map them. The given presences are set for fields that are not null.
+func decodeModel(data []byte, v interface{}, presences ...presence) (extra map[string]json.RawMessage, err error) {
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	known := knownFields(rv.Type())
+
+	for key, raw := range fields {
+		name, ok := known.lookup(key)
+		if !ok {
+			if extra == nil {
+				extra = map[string]json.RawMessage{}
+			}
+
+			extra[key] = raw
+
+			continue
+		}
+
+		if err = json.Unmarshal(raw, rv.Field(known.index[name]).Addr().Interface()); err != nil {
+			// Report the field like encoding/json does for the whole object
+			if te, ok := err.(*json.UnmarshalTypeError); ok && te.Field == "" {
+				te.Struct, te.Field = rv.Type().Name(), name
+			}
+
+			return nil, err
+		}
+
+		for _, p := range presences {
+			if p.key == name {
+				*p.set = string(raw) != "null"
+			}
+		}
+	}
+
+	return
+}
+
+// encodeModel encodes v, which must be an alias type of a model without its own MarshalJSON,
+// and appends the extra fields that are not mapped by the model.
+func encodeModel(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	known := knownFields(reflect.Indirect(reflect.ValueOf(v)).Type())
+
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if _, ok := known.lookup(k); !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)+64*len(keys)))
+	buf.Write(data[:len(data)-1])
+
+	for _, k := range keys {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(k)
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		if err := json.Compact(buf, extra[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// modelKeys contains the json keys of a model type and the index of their field.
+type modelKeys struct {
+	index map[string]int
+
+	// Lowercase keys, for the case-insensitive match of encoding/json
+	folded map[string]string
+}
+
+// lookup returns the canonical key for the given key, which like encoding/json
+// is matched case-insensitive if there is no exact match.
+func (m *modelKeys) lookup(key string) (name string, ok bool) {
+	if _, ok = m.index[key]; ok {
+		return key, true
+	}
+
+	name, ok = m.folded[strings.ToLower(key)]
+
+	return
+}
+
+// knownFields returns the json keys of the exported fields of the struct type.
+func knownFields(t reflect.Type) *modelKeys {
+	if known, ok := modelFields.Load(t); ok {
+		return known.(*modelKeys)
+	}
+
+	known := &modelKeys{index: map[string]int{}, folded: map[string]string{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+
+		known.index[name] = i
+
+		// The first field wins, like encoding/json
+		if _, ok := known.folded[strings.ToLower(name)]; !ok {
+			known.folded[strings.ToLower(name)] = name
+		}
+	}
+
+	modelFields.Store(t, known)
+
+	return known
+}
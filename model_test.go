@@ -0,0 +1,128 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClientNode_UnmarshalJSON(t *testing.T) {
+	var node ClientNode
+
+	err := json.Unmarshal([]byte(`{
+		"cid": 123,
+		"alias": null,
+		"comment": "",
+		"user_ref": "jane.doe",
+		"online": true,
+		"os": "linux"
+	}`), &node)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), node.ClientID)
+	assert.False(t, node.HasAlias())
+	assert.True(t, node.HasComment())
+	assert.Equal(t, "jane.doe", node.UserRef.String())
+	assert.Equal(t, map[string]json.RawMessage{"os": json.RawMessage(`"linux"`)}, node.Extra)
+
+	// nodes that are not decoded
+	assert.True(t, (&ClientNode{Alias: "alpha"}).HasAlias())
+	assert.False(t, (&ClientNode{}).HasComment())
+}
+
+func TestSessionNode_UnmarshalJSON(t *testing.T) {
+	var node SessionNode
+
+	err := json.Unmarshal([]byte(`{
+		"sid": "S1",
+		"comment": null,
+		"src_user_ref": "jane.doe",
+		"dst_user_ref": {"id": 5},
+		"from": {"cid": 100, "alias": null, "client-version": "6.0.1"},
+		"to": {"cid": 200, "alias": "beta"}
+	}`), &node)
+
+	assert.NoError(t, err)
+	assert.False(t, node.HasComment())
+	assert.Equal(t, "jane.doe", node.SourceUserRef.String())
+	assert.Equal(t, `{"id": 5}`, node.TargetUserRef.String())
+	assert.Nil(t, node.Extra)
+	assert.Equal(t, "6.0.1", node.Source.ClientVersion)
+	assert.False(t, node.Source.HasAlias())
+	assert.True(t, node.Target.HasAlias())
+
+	var empty *UserRef
+	assert.Equal(t, "", empty.String())
+}
+
+func TestClientNode_MarshalJSON(t *testing.T) {
+	var node ClientNode
+
+	err := json.Unmarshal([]byte(`{
+		"cid": 123,
+		"Alias": "alpha",
+		"os": "linux",
+		"tags": ["a", "b,c", {"x": "}"}],
+		"esc\"aped": 1
+	}`), &node)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha", node.Alias)
+	assert.True(t, node.HasAlias())
+	assert.Equal(t, json.RawMessage(`["a", "b,c", {"x": "}"}]`), node.Extra["tags"])
+	assert.Equal(t, json.RawMessage(`1`), node.Extra[`esc"aped`])
+
+	// unknown fields survive a round-trip
+	data, err := json.Marshal([]ClientNode{node})
+	assert.NoError(t, err)
+
+	var list []ClientNode
+	assert.NoError(t, json.Unmarshal(data, &list))
+	assert.Len(t, list[0].Extra, 3)
+
+	for k, raw := range node.Extra {
+		assert.JSONEq(t, string(raw), string(list[0].Extra[k]), k)
+	}
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data[1:len(data)-1], &fields))
+	assert.Equal(t, "linux", fields["os"])
+	assert.Equal(t, float64(123), fields["cid"])
+
+	// without unknown fields the regular encoding is used
+	plain, err := json.Marshal(&SessionNode{SessionID: "S1"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(plain), `"sid":"S1"`)
+}
+
+func TestClientDetailRequest_UserRefs(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/client_detail.json")
+	defer server.Close()
+
+	resp, err := NewClientDetailRequest(100000000).Do(NewAPITestClient(t, server, "", ""))
+	assert.NoError(t, err)
+
+	assert.Nil(t, resp.UserRef)
+	assert.Nil(t, resp.Extra)
+	assert.True(t, resp.HasAlias())
+	assert.Nil(t, resp.LastSessions[0].SourceUserRef)
+	assert.False(t, resp.LastSessions[0].HasComment())
+	assert.False(t, resp.LastSessions[0].Target.HasAlias())
+}
+
+func TestClientNode_UnmarshalJSONErrors(t *testing.T) {
+	var node ClientNode
+
+	err := json.Unmarshal([]byte(`{"cid": "123"}`), &node)
+	if assert.IsType(t, &json.UnmarshalTypeError{}, err) {
+		assert.Equal(t, "cid", err.(*json.UnmarshalTypeError).Field)
+	}
+
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &node))
+
+	// keys are matched case-insensitive like encoding/json
+	assert.NoError(t, json.Unmarshal([]byte(`{"CLIENT-VERSION": "6.0.1", "COMMENT": null}`), &node))
+	assert.Equal(t, "6.0.1", node.ClientVersion)
+	assert.False(t, node.HasComment())
+	assert.Nil(t, node.Extra)
+}
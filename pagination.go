@@ -19,7 +19,7 @@ type PaginationOptions struct {
 	// Result limit, use anydesk.Infinite for unlimited results
 	Limit int64 `json:"-"`
 
-	// Result sort by property name
+	// Result sort by property name, i.e. anydesk.ClientSortAlias
 	Sort string `json:"-"`
 
 	// Result sort order, use anydesk.OrderAsc or anydesk.OrderDesc
@@ -36,6 +36,33 @@ func NewPaginationOptions() *PaginationOptions {
 	}
 }
 
+// validate checks the pagination settings, sort is limited to the given properties.
+func (po *PaginationOptions) validate(sortable ...string) error {
+	if po.Offset < 0 {
+		return invalid("Offset", "%d is negative", po.Offset)
+	}
+
+	if po.Limit < 0 && po.Limit != Infinite {
+		return invalid("Limit", "%d is negative, use anydesk.Infinite for unlimited results", po.Limit)
+	}
+
+	if po.Order != "" && po.Order != OrderAsc && po.Order != OrderDesc {
+		return invalid("Order", "unknown sort order %q", po.Order)
+	}
+
+	if po.Sort == "" {
+		return nil
+	}
+
+	for _, s := range sortable {
+		if po.Sort == s {
+			return nil
+		}
+	}
+
+	return invalid("Sort", "unknown sort property %q", po.Sort)
+}
+
 // GetPaginationOptions returns the currently configured pagination settings.
 func (po *PaginationOptions) GetPaginationOptions() *PaginationOptions {
 	return po
@@ -44,16 +71,16 @@ func (po *PaginationOptions) GetPaginationOptions() *PaginationOptions {
 // PaginatedResult contains the current pagination settings as returned by the API.
 type PaginatedResult struct {
 	// Total result count
-	Count int64 `json:"count"`
+	Count int64 `json:"count" schema:"required"`
 
 	// Unknown
-	Selected int64 `json:"selected"`
+	Selected int64 `json:"selected" schema:"required"`
 
 	// Applied offset of the current result
-	Offset int64 `json:"offset"`
+	Offset int64 `json:"offset" schema:"required"`
 
 	// Applied limit of the current result
-	Limit int64 `json:"limit"`
+	Limit int64 `json:"limit" schema:"required"`
 }
 
 // HasMore will indicate if more results could be fetched and also return
@@ -0,0 +1,101 @@
+package anydesk
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of API requests.
+// API.Do calls Wait before every request that is sent to the API.
+type RateLimiter interface {
+	// Wait blocks until the next request may be sent.
+	Wait()
+}
+
+// clockedRateLimiter is implemented by rate limiters that use the Clock of the API, unless configured otherwise.
+type clockedRateLimiter interface {
+	wait(fallback Clock)
+}
+
+// waitFor waits for the rate limiter, using the given clock if the limiter has none configured.
+func waitFor(limiter RateLimiter, fallback Clock) {
+	if l, ok := limiter.(clockedRateLimiter); ok {
+		l.wait(fallback)
+		return
+	}
+
+	limiter.Wait()
+}
+
+// TokenBucket is a RateLimiter that allows bursts of requests up to a given size,
+// refilled at a fixed rate.
+type TokenBucket struct {
+	// Source of the current time and waiting, defaults to the Clock of the API
+	// or, used on its own, to SystemClock.
+	Clock Clock
+
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a token bucket that allows the given requests per second on average,
+// with bursts of up to burst requests. A burst smaller than 1 is treated as 1.
+func NewRateLimiter(requestsPerSecond float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until a token is available and takes it.
+func (tb *TokenBucket) Wait() {
+	tb.wait(SystemClock)
+}
+
+// wait blocks until a token is available, using the fallback clock if none is configured.
+func (tb *TokenBucket) wait(fallback Clock) {
+	clock := tb.Clock
+	if clock == nil {
+		clock = fallback
+	}
+
+	if d := tb.reserve(clock.Now()); d > 0 {
+		clock.Sleep(d)
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait until it may be used.
+func (tb *TokenBucket) reserve(now time.Time) time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.rate <= 0 {
+		return 0
+	}
+
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens += elapsed.Seconds() * tb.rate
+		tb.last = now
+	}
+
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+
+	tb.tokens--
+
+	if tb.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
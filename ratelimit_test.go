@@ -0,0 +1,60 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_reserve(t *testing.T) {
+	tb := NewRateLimiter(2, 3)
+	now := tb.last
+
+	// the burst is available immediately
+	assert.Equal(t, time.Duration(0), tb.reserve(now))
+	assert.Equal(t, time.Duration(0), tb.reserve(now))
+	assert.Equal(t, time.Duration(0), tb.reserve(now))
+
+	// afterwards requests are spaced by the rate
+	assert.Equal(t, 500*time.Millisecond, tb.reserve(now))
+	assert.Equal(t, time.Second, tb.reserve(now))
+
+	// tokens refill over time, but never above the burst
+	assert.Equal(t, time.Duration(0), tb.reserve(now.Add(10*time.Second)))
+	assert.InDelta(t, 2, tb.tokens, 0.001)
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	clock := newTestClock()
+	start := clock.Now()
+
+	tb := NewRateLimiter(100, 1)
+	tb.Clock = clock
+
+	tb.Wait()
+	tb.Wait()
+	tb.Wait()
+
+	// the first token is available, the others are spaced by the rate
+	assert.Equal(t, start.Add(20*time.Millisecond), clock.Now())
+}
+
+func TestAPI_Do_RateLimiter(t *testing.T) {
+	limiter := &countingLimiter{}
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.APIEndpoint = "http://127.0.0.1:0"
+	api.RateLimiter = limiter
+
+	_, _ = NewAuthenticationRequest().Do(api)
+
+	assert.Equal(t, 1, limiter.calls)
+}
+
+type countingLimiter struct {
+	calls int
+}
+
+func (l *countingLimiter) Wait() {
+	l.calls++
+}
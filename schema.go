@@ -0,0 +1,225 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DriftKind is the kind of difference between a response and the known schema.
+type DriftKind int
+
+const (
+	// DriftUnknownField is a field of the response that is not part of the known schema.
+	DriftUnknownField DriftKind = iota
+
+	// DriftMissingField is a required field that is missing in the response.
+	DriftMissingField
+
+	// DriftDeprecatedField is a field of the response that is undocumented or deprecated.
+	DriftDeprecatedField
+)
+
+func (k DriftKind) String() string {
+	switch k {
+	case DriftUnknownField:
+		return "unknown field"
+	case DriftMissingField:
+		return "missing field"
+	case DriftDeprecatedField:
+		return "deprecated field"
+	default:
+		return fmt.Sprintf("DriftKind(%d)", int(k))
+	}
+}
+
+// SchemaDrift is a single difference between a response and the known schema, see API.StrictDecoding.
+type SchemaDrift struct {
+	Kind DriftKind
+
+	// Path of the field, list elements are marked with "[]", i.e. "list[].from.os".
+	Field string
+}
+
+func (d SchemaDrift) String() string {
+	return fmt.Sprintf("%s %s", d.Kind, d.Field)
+}
+
+// decode unmarshals the response body into v. With API.StrictDecoding the body is compared with the
+// schema of v, differences are added to the request meta and reported to API.OnSchemaDrift.
+func (api *API) decode(request APIRequest, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return err
+	}
+
+	if !api.StrictDecoding {
+		return nil
+	}
+
+	drift := schemaDrift(body, reflect.TypeOf(v))
+	if len(drift) == 0 {
+		return nil
+	}
+
+	base := request.GetRequestDetails()
+	if base.meta != nil {
+		base.meta.Drift = drift
+	}
+
+	if api.OnSchemaDrift != nil {
+		api.OnSchemaDrift(resourcePath(base.Resource), drift)
+	}
+
+	return nil
+}
+
+// schemaField describes a json field of a response type.
+type schemaField struct {
+	typ        reflect.Type
+	required   bool
+	deprecated bool
+}
+
+// schemaFields contains the fields of the response types, by type.
+var schemaFields sync.Map
+
+// fieldsOf returns the json fields of the struct type, including those of embedded structs.
+// Fields are tagged with `schema:"required"` or `schema:"deprecated"`.
+func fieldsOf(t reflect.Type) map[string]schemaField {
+	if fields, ok := schemaFields.Load(t); ok {
+		return fields.(map[string]schemaField)
+	}
+
+	fields := map[string]schemaField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// Fields of embedded structs are promoted
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for k, v := range fieldsOf(ft) {
+				fields[k] = v
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := f.Tag.Get("schema")
+
+		fields[name] = schemaField{
+			typ:        f.Type,
+			required:   strings.Contains(schema, "required"),
+			deprecated: strings.Contains(schema, "deprecated"),
+		}
+	}
+
+	schemaFields.Store(t, fields)
+
+	return fields
+}
+
+// schemaDrift compares the json body with the schema of the given type.
+// Each difference is reported once, regardless of the number of list elements showing it.
+func schemaDrift(body []byte, t reflect.Type) []SchemaDrift {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+
+	w := &driftWalker{seen: map[SchemaDrift]bool{}}
+	w.walk(v, t, "")
+
+	sort.Slice(w.drift, func(i, j int) bool {
+		if w.drift[i].Field != w.drift[j].Field {
+			return w.drift[i].Field < w.drift[j].Field
+		}
+
+		return w.drift[i].Kind < w.drift[j].Kind
+	})
+
+	return w.drift
+}
+
+type driftWalker struct {
+	drift []SchemaDrift
+	seen  map[SchemaDrift]bool
+}
+
+func (w *driftWalker) add(kind DriftKind, field string) {
+	d := SchemaDrift{Kind: kind, Field: field}
+
+	if !w.seen[d] {
+		w.seen[d] = true
+		w.drift = append(w.drift, d)
+	}
+}
+
+func (w *driftWalker) walk(v interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		fields := fieldsOf(t)
+
+		for key, value := range obj {
+			f, ok := fields[key]
+			if !ok {
+				w.add(DriftUnknownField, joinPath(path, key))
+				continue
+			}
+
+			if f.deprecated {
+				w.add(DriftDeprecatedField, joinPath(path, key))
+			}
+
+			w.walk(value, f.typ, joinPath(path, key))
+		}
+
+		for key, f := range fields {
+			if _, ok := obj[key]; f.required && !ok {
+				w.add(DriftMissingField, joinPath(path, key))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+
+		for _, item := range list {
+			w.walk(item, t.Elem(), path+"[]")
+		}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
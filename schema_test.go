@@ -0,0 +1,96 @@
+package anydesk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI_StrictDecoding(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/sysinfo.json")
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	resp, err := NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.Meta.Drift)
+
+	var reported []SchemaDrift
+	api.StrictDecoding = true
+	api.OnSchemaDrift = func(resource string, drift []SchemaDrift) {
+		assert.Equal(t, "/sysinfo", resource)
+		reported = drift
+	}
+
+	resp, err = NewSysinfoRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaDrift{
+		{Kind: DriftDeprecatedField, Field: "license.has-expired"},
+		{Kind: DriftDeprecatedField, Field: "license.power-user"},
+	}, resp.Meta.Drift)
+	assert.Equal(t, resp.Meta.Drift, reported)
+	assert.Equal(t, "deprecated field license.power-user", reported[1].String())
+}
+
+func TestAPI_StrictDecoding_Fixtures(t *testing.T) {
+	tests := map[string]func(api *API) (*ResponseMeta, error){
+		"./_tests/auth_response.json": func(api *API) (*ResponseMeta, error) {
+			r, err := NewAuthenticationRequest().Do(api)
+			return r.Meta, err
+		},
+		"./_tests/client_detail.json": func(api *API) (*ResponseMeta, error) {
+			r, err := NewClientDetailRequest(100000000).Do(api)
+			return r.Meta, err
+		},
+		"./_tests/client_list_all.json": func(api *API) (*ResponseMeta, error) {
+			r, err := NewClientListRequest(nil).Do(api)
+			return r.Meta, err
+		},
+		"./_tests/session_list.json": func(api *API) (*ResponseMeta, error) {
+			r, err := NewSessionListRequest(nil).Do(api)
+			return r.Meta, err
+		},
+	}
+
+	for fixture, do := range tests {
+		server := newFixtureServer(t, fixture)
+
+		api := NewAPITestClient(t, server, "", "")
+		api.StrictDecoding = true
+
+		meta, err := do(api)
+		assert.NoError(t, err, fixture)
+		assert.Nil(t, meta.Drift, fixture)
+
+		server.Close()
+	}
+}
+
+func TestAPI_StrictDecoding_Drift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{
+			"count": 2, "selected": 2, "offset": 0, "limit": -1, "region": "eu",
+			"list": [
+				{"active": false, "sid": "S1", "from": {"cid": 1, "alias": null, "os": "linux"}, "to": {"cid": 2, "alias": null},
+				 "start-time": 1, "end-time": 2, "comment": null},
+				{"active": false, "sid": "S2", "from": {"cid": 1, "alias": null, "os": "linux"}, "to": {"cid": 2, "alias": null},
+				 "start-time": 1, "end-time": 2, "comment": null}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+	api.StrictDecoding = true
+
+	resp, err := NewSessionListRequest(nil).Do(api)
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 2)
+	assert.Equal(t, []SchemaDrift{
+		{Kind: DriftMissingField, Field: "list[].duration"},
+		{Kind: DriftUnknownField, Field: "list[].from.os"},
+		{Kind: DriftUnknownField, Field: "region"},
+	}, resp.Meta.Drift)
+}
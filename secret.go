@@ -0,0 +1,103 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/adrianrudnik/anydesk/internal/redact"
+)
+
+// Redacted replaces secrets in formatted, logged and marshalled values.
+const Redacted = redact.Redacted
+
+// redacted returns Redacted for a secret, or an empty string for an empty one, so missing values remain visible.
+func redacted(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return Redacted
+}
+
+// String returns a description of the API configuration without the password.
+func (api API) String() string {
+	return fmt.Sprintf("anydesk.API{LicenseID: %s, APIPassword: %s, APIEndpoint: %s}", api.LicenseID, redacted(api.APIPassword), api.APIEndpoint)
+}
+
+// GoString returns a description of the API configuration without the password.
+func (api API) GoString() string {
+	return fmt.Sprintf("anydesk.API{LicenseID:%q, APIPassword:%q, APIEndpoint:%q}", api.LicenseID, redacted(api.APIPassword), api.APIEndpoint)
+}
+
+// Format redacts the password for all verbs.
+func (api API) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, api.String(), api.GoString())
+}
+
+// MarshalJSON returns the API configuration without the password.
+func (api API) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		LicenseID   string   `json:"license_id"`
+		APIPassword string   `json:"api_password"`
+		APIEndpoint string   `json:"api_endpoint"`
+		Endpoints   []string `json:"api_endpoints,omitempty"`
+	}{
+		LicenseID:   api.LicenseID,
+		APIPassword: redacted(api.APIPassword),
+		APIEndpoint: api.APIEndpoint,
+		Endpoints:   api.Endpoints,
+	})
+}
+
+// String returns a description of the license without the license key and API password.
+func (l License) String() string {
+	return fmt.Sprintf(
+		"{Name:%s ExpiresTimestamp:%d HasExpired:%t MaxClients:%d MaxSessions:%d MaxSessionTime:%d "+
+			"Namespaces:%v ID:%s Key:%s APIPassword:%s PowerUser:%t}",
+		l.Name, l.ExpiresTimestamp, l.HasExpired, l.MaxClients, l.MaxSessions, l.MaxSessionTime,
+		l.Namespaces, l.ID, redacted(l.Key), redacted(l.APIPassword), l.PowerUser,
+	)
+}
+
+// GoString returns a description of the license without the license key and API password.
+func (l License) GoString() string {
+	return fmt.Sprintf(
+		"anydesk.License{Name:%q, ExpiresTimestamp:%d, HasExpired:%t, MaxClients:%d, MaxSessions:%d, MaxSessionTime:%d, "+
+			"Namespaces:%#v, ID:%q, Key:%q, APIPassword:%q, PowerUser:%t}",
+		l.Name, l.ExpiresTimestamp, l.HasExpired, l.MaxClients, l.MaxSessions, l.MaxSessionTime,
+		l.Namespaces, l.ID, redacted(l.Key), redacted(l.APIPassword), l.PowerUser,
+	)
+}
+
+// MarshalJSON encodes the license with the license key and API password redacted.
+func (l License) MarshalJSON() ([]byte, error) {
+	type plain License
+
+	p := plain(l)
+	p.Key = redacted(l.Key)
+	p.APIPassword = redacted(l.APIPassword)
+
+	return json.Marshal(p)
+}
+
+// Reveal returns the license key and API password in clear text, they are redacted everywhere else.
+func (l License) Reveal() (key string, apiPassword string) {
+	return l.Key, l.APIPassword
+}
+
+// Format redacts the license key and API password for all verbs.
+func (l License) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, l.String(), l.GoString())
+}
+
+// formatRedacted writes the Go syntax representation for "%#v" and the plain one for all other verbs,
+// so no verb falls back to printing the fields.
+func formatRedacted(f fmt.State, verb rune, plain string, goSyntax string) {
+	if verb == 'v' && f.Flag('#') {
+		_, _ = io.WriteString(f, goSyntax)
+		return
+	}
+
+	_, _ = io.WriteString(f, plain)
+}
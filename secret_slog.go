@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package anydesk
+
+import "log/slog"
+
+// LogValue returns the API configuration without the password for structured logging.
+func (api API) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("license_id", api.LicenseID),
+		slog.String("api_password", redacted(api.APIPassword)),
+		slog.String("api_endpoint", api.APIEndpoint),
+	)
+}
+
+// LogValue returns the license without the license key and API password for structured logging.
+func (l License) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", l.Name),
+		slog.String("license_id", l.ID),
+		slog.String("license_key", redacted(l.Key)),
+		slog.String("api_password", redacted(l.APIPassword)),
+		slog.Int64("expires", l.ExpiresTimestamp),
+		slog.Int("max_clients", l.MaxClients),
+		slog.Int("max_sessions", l.MaxSessions),
+	)
+}
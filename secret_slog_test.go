@@ -0,0 +1,24 @@
+//go:build go1.21
+// +build go1.21
+
+package anydesk
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestAPI_LogValue(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+
+	license := License{ID: "LICENSE", Key: "KEY_SECRET", APIPassword: "TOP_SECRET"}
+	logger.Info("configured", "api", NewAPI("LICENSE", "TOP_SECRET"), "license", license)
+
+	assert.NotContains(t, out.String(), "TOP_SECRET")
+	assert.NotContains(t, out.String(), "KEY_SECRET")
+	assert.Contains(t, out.String(), `"api":{"license_id":"LICENSE","api_password":"[REDACTED]"`)
+	assert.Contains(t, out.String(), `"license_key":"[REDACTED]","api_password":"[REDACTED]"`)
+}
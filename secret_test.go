@@ -0,0 +1,66 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var redactionFormats = []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d", "%10s"}
+
+func TestAPI_Redaction(t *testing.T) {
+	api := NewAPI("LICENSE", "TOP_SECRET")
+
+	for _, format := range redactionFormats {
+		out := fmt.Sprintf(format, api)
+		assert.NotContains(t, out, "TOP_SECRET", format)
+		assert.Contains(t, out, "LICENSE", format)
+	}
+
+	assert.Equal(t, "TOP_SECRET", api.APIPassword)
+	assert.Equal(t, `anydesk.API{LicenseID:"LICENSE", APIPassword:"[REDACTED]", APIEndpoint:"https://v1.api.anydesk.com:8081"}`, fmt.Sprintf("%#v", api))
+
+	// values are redacted as well as pointers
+	for _, v := range []interface{}{api, *api} {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"license_id": "LICENSE",
+			"api_password": "[REDACTED]",
+			"api_endpoint": "https://v1.api.anydesk.com:8081"
+		}`, string(data))
+	}
+
+	assert.NotContains(t, fmt.Sprintf("%+v", *api), "TOP_SECRET")
+
+	// missing passwords stay visible as such
+	assert.Contains(t, NewAPI("LICENSE", "").String(), "APIPassword: ,")
+}
+
+func TestLicense_Redaction(t *testing.T) {
+	info := &SysinfoResponse{License: License{ID: "LICENSE", Key: "KEY_SECRET", APIPassword: "TOP_SECRET"}}
+
+	for _, format := range redactionFormats {
+		for _, v := range []interface{}{info, info.License, &info.License} {
+			out := fmt.Sprintf(format, v)
+			assert.NotContains(t, out, "TOP_SECRET", format)
+			assert.NotContains(t, out, "KEY_SECRET", format)
+		}
+	}
+
+	assert.Contains(t, fmt.Sprintf("%+v", info), "Key:[REDACTED] APIPassword:[REDACTED]")
+	assert.Contains(t, fmt.Sprintf("%#v", info.License), `ID:"LICENSE", Key:"[REDACTED]", APIPassword:"[REDACTED]"`)
+
+	for _, v := range []interface{}{info, *info, info.License} {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "TOP_SECRET")
+		assert.Contains(t, string(data), `"license-key":"[REDACTED]","api-password":"[REDACTED]"`)
+	}
+
+	// the secrets are only available on request
+	key, password := info.License.Reveal()
+	assert.Equal(t, "KEY_SECRET", key)
+	assert.Equal(t, "TOP_SECRET", password)
+}
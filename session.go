@@ -3,37 +3,83 @@ package anydesk
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/url"
 	"strconv"
 	"time"
 )
 
+// Sortable properties of session lists, see PaginationOptions.Sort.
+const (
+	SessionSortID        = "sid"
+	SessionSortStartTime = "start-time"
+	SessionSortEndTime   = "end-time"
+	SessionSortDuration  = "duration"
+	SessionSortComment   = "comment"
+)
+
 // SessionNode is the common structure of session information produced by AnyDesk clients.
 type SessionNode struct {
 	// Indicatges of the session is currently active.
-	Active bool `json:"active"`
+	Active bool `json:"active" schema:"required"`
 
 	// The unique sesson ID for this connection.
-	SessionID string `json:"sid"`
+	SessionID string `json:"sid" schema:"required"`
 
 	// The source client responsible for this session.
-	Source *ClientSlimNode `json:"from"`
+	Source *ClientSlimNode `json:"from" schema:"required"`
 
 	// The connected client of the session.
-	Target *ClientSlimNode `json:"to"`
+	Target *ClientSlimNode `json:"to" schema:"required"`
 
 	// Connection start as unix-timestamp.
-	StartTimestamp int64 `json:"start-time"`
+	StartTimestamp int64 `json:"start-time" schema:"required"`
 
 	// Connection end as unix-timestamp.
-	EndTimestamp int64 `json:"end-time"`
+	EndTimestamp int64 `json:"end-time" schema:"required"`
 
 	// Total duration of the session in seconds.
-	DurationInSeconds int64 `json:"duration"`
+	DurationInSeconds int64 `json:"duration" schema:"required"`
 
 	// The comment left by the source client.
-	Comment string `json:"comment"`
+	// Empty if the session has no comment, see HasComment.
+	Comment string `json:"comment" schema:"required"`
+
+	// User the source client is assigned to, nil if none.
+	SourceUserRef *UserRef `json:"src_user_ref"`
+
+	// User the target client is assigned to, nil if none.
+	TargetUserRef *UserRef `json:"dst_user_ref"`
+
+	// Fields returned by the API that are not mapped by this struct.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	hasComment bool
+}
+
+// UnmarshalJSON decodes the node and keeps unknown fields in Extra.
+func (n *SessionNode) UnmarshalJSON(data []byte) error {
+	type plain SessionNode
+
+	extra, err := decodeModel(data, (*plain)(n), presence{key: "comment", set: &n.hasComment})
+	if err != nil {
+		return err
+	}
+
+	n.Extra = extra
+
+	return nil
+}
+
+// MarshalJSON encodes the node including the unknown fields kept in Extra.
+func (n SessionNode) MarshalJSON() ([]byte, error) {
+	type plain SessionNode
+
+	return encodeModel(plain(n), n.Extra)
+}
+
+// HasComment reports whether the session has a comment. Nodes returned by the API have none if it is null.
+func (n *SessionNode) HasComment() bool {
+	return n.hasComment || n.Comment != ""
 }
 
 // StartTime returns the connection start time.
@@ -71,21 +117,25 @@ type SessionCommentChangeRequest struct {
 	Comment *string `json:"comment"`
 }
 
-// Do will execute the "/auth" query against the given API.
-func (req *SessionCommentChangeRequest) Do(api *API) (err error) {
-	// Execute the request by handing it over to the given API configuration
-	body, err := api.Do(req)
-	if err != nil {
-		return
+func (req *SessionCommentChangeRequest) requiredCapabilities() []Capability {
+	return []Capability{CapabilitySessionComment}
+}
+
+// Validate checks the session ID of the resource.
+func (req *SessionCommentChangeRequest) Validate() error {
+	if req.BaseRequest == nil {
+		return invalid("Resource", "must not be empty")
 	}
 
-	data, err := json.Marshal(body)
-	if err != nil {
-		return
+	if resourceID(req.Resource, "/sessions/") == "" {
+		return invalid("SessionID", "must not be empty")
 	}
 
-	fmt.Println(string(data))
+	return req.BaseRequest.Validate()
+}
 
+// Do will execute the "/sessions/{id}" patch against the given API.
+func (req *SessionCommentChangeRequest) Do(api *API) (err error) {
 	_, err = api.Do(req)
 	return
 }
@@ -100,11 +150,11 @@ func NewSessionCommentChangeRequest(session string, comment string) *SessionComm
 	}
 
 	return &SessionCommentChangeRequest{
-		&BaseRequest{
+		BaseRequest: &BaseRequest{
 			Method:   "PATCH",
 			Resource: fmt.Sprintf("/sessions/%s", session),
 		},
-		v,
+		Comment: v,
 	}
 }
 
@@ -112,6 +162,8 @@ func NewSessionCommentChangeRequest(session string, comment string) *SessionComm
 type SessionListRequest struct {
 	*BaseRequest
 	*PaginationOptions
+
+	search *SessionListSearch
 }
 
 // SessionListSearch configures the search  params for NewSessionListRequest()
@@ -130,11 +182,17 @@ type SessionListSearch struct {
 }
 
 // Do will execute the "/sessions" query against the given API.
-func (req *SessionListRequest) Do(api *API) (err error) {
+func (req *SessionListRequest) Do(api *API) (r *SessionListResponse, err error) {
+	r = newSessionListResponse()
+
 	body, err := api.DoPaginated(req)
+	r.Meta = req.Meta()
 
-	ioutil.WriteFile("out.json", body, 0644)
+	if err != nil {
+		return
+	}
 
+	err = api.decode(req, body, r)
 	if err != nil {
 		return
 	}
@@ -142,11 +200,87 @@ func (req *SessionListRequest) Do(api *API) (err error) {
 	return
 }
 
+func (req *SessionListRequest) requiredCapabilities() []Capability {
+	list := []Capability{CapabilitySessionList}
+
+	if s := req.search; s != nil {
+		if s.Direction != "" {
+			list = append(list, CapabilitySessionDirectionFilter)
+		}
+
+		if !s.TimeFrom.IsZero() || !s.TimeTo.IsZero() {
+			list = append(list, CapabilitySessionTimeFilter)
+		}
+	}
+
+	return list
+}
+
+// Validate checks the search and pagination settings.
+func (req *SessionListRequest) Validate() error {
+	if err := req.search.validate(); err != nil {
+		return err
+	}
+
+	err := req.PaginationOptions.validate(
+		SessionSortID,
+		SessionSortStartTime,
+		SessionSortEndTime,
+		SessionSortDuration,
+		SessionSortComment,
+	)
+	if err != nil {
+		return err
+	}
+
+	return req.BaseRequest.Validate()
+}
+
+// validate checks the search parameters, a nil search is valid.
+func (s *SessionListSearch) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.ClientID < 0 {
+		return invalid("ClientID", "%d is not a valid client ID", s.ClientID)
+	}
+
+	switch s.Direction {
+	case "", DirectionIn, DirectionInOut, DirectionOut:
+	default:
+		return invalid("Direction", "unknown session direction %q", s.Direction)
+	}
+
+	if !s.TimeFrom.IsZero() && !s.TimeTo.IsZero() && s.TimeFrom.After(s.TimeTo) {
+		return invalid("TimeFrom", "%s is after TimeTo", s.TimeFrom.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Each executes the request and calls fn for every session while the response is decoded,
+// without holding the whole list in memory. An error returned by fn stops the iteration.
+func (req *SessionListRequest) Each(api *API, fn func(node *SessionNode) error) (*PaginatedResult, error) {
+	return api.DoStream(req, func(dec *json.Decoder) error {
+		node := &SessionNode{}
+		if err := dec.Decode(node); err != nil {
+			return err
+		}
+
+		return fn(node)
+	})
+}
+
 // NewSessionListRequest returns a new session list query.
 func NewSessionListRequest(search *SessionListSearch) *SessionListRequest {
 	// Handle search
 	var q *url.Values
 	if search != nil {
+		// Keep a copy for Validate, matching the query
+		s := *search
+		search = &s
+
 		q = &url.Values{}
 
 		if search.ClientID > 0 {
@@ -175,5 +309,20 @@ func NewSessionListRequest(search *SessionListSearch) *SessionListRequest {
 			Query:    q,
 		},
 		PaginationOptions: NewPaginationOptions(),
+		search:            search,
 	}
 }
+
+// SessionListResponse contains all fields available for session lists from the API resource.
+type SessionListResponse struct {
+	*PaginatedResult
+
+	// Details about the http exchange.
+	Meta *ResponseMeta `json:"-"`
+
+	List []SessionNode `json:"list" schema:"required"`
+}
+
+func newSessionListResponse() *SessionListResponse {
+	return &SessionListResponse{}
+}
@@ -1,6 +1,85 @@
 package anydesk
 
-import "testing"
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
 
 func TestNewSessionCommentChangeRequest(t *testing.T) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		body := make([]byte, req.ContentLength)
+		_, _ = req.Body.Read(body)
+
+		assert.Equal(t, "PATCH", req.Method)
+		assert.Equal(t, "/sessions/SESSION1", req.URL.String())
+		assert.JSONEq(t, `{"comment":"TEST_COMMENT"}`, string(body))
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewAPITestClient(t, server, "", "")
+
+	err := NewSessionCommentChangeRequest("SESSION1", "TEST_COMMENT").Do(client)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewSessionCommentChangeRequest_Remove(t *testing.T) {
+	req := NewSessionCommentChangeRequest("SESSION1", "")
+	assert.Nil(t, req.Comment)
+}
+
+func TestNewSessionListRequest(t *testing.T) {
+	server := NewAPITestServer(
+		t,
+		"/sessions?cid=100000000&direction=in&from=1590504000&limit=2&offset=10&order=desc&to=1590505000",
+		"./_tests/session_list.json",
+		http.StatusOK,
+	)
+	defer server.Close()
+
+	client := NewAPITestClient(t, server, "", "")
+
+	req := NewSessionListRequest(&SessionListSearch{
+		ClientID:  100000000,
+		Direction: DirectionIn,
+		TimeFrom:  time.Unix(1590504000, 0),
+		TimeTo:    time.Unix(1590505000, 0),
+	})
+	req.Offset = 10
+	req.Limit = 2
+
+	resp, err := req.Do(client)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal(int64(42), resp.Count)
+	a.Equal(int64(2), resp.Selected)
+	a.Equal(int64(10), resp.Offset)
+	a.Len(resp.List, 2)
+
+	s1 := resp.List[0]
+	a.Equal("SESSION1", s1.SessionID)
+	a.Equal("TEST-COMMENT1", s1.Comment)
+	a.Equal(time.Minute, s1.Duration())
+	a.Equal("TEST_ALIAS1", s1.Source.Alias)
+	a.Equal(int64(100000001), s1.Target.ClientID)
+
+	s2 := resp.List[1]
+	a.True(s2.Active)
+	a.Equal("", s2.Comment)
+	a.Equal(int64(100000000), s2.Target.ClientID)
+
+	next, hasMore := resp.HasMore(req)
+	a.True(hasMore)
+	a.Equal(int64(12), next.Offset)
 }
@@ -0,0 +1,180 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// DoStream executes a paginated request and passes the decoder to item once per element of the
+// "list" array, while the response is read. Unlike DoPaginated, the response body is never held in
+// memory as a whole, which matters for large or Infinite lists. The pagination details are decoded
+// in the same pass. Streamed responses are neither cached nor coalesced.
+//
+// An error returned by item stops the decoding and is returned as it is.
+func (api *API) DoStream(request PaginatedAPIRequest, item func(dec *json.Decoder) error) (p *PaginatedResult, err error) {
+	paginate(request)
+
+	p = &PaginatedResult{}
+	base := request.GetRequestDetails()
+
+	base.stream = func(r io.Reader) error {
+		return decodeList(r, p, item)
+	}
+
+	defer func() {
+		base.stream = nil
+	}()
+
+	if _, err = api.Do(request); err != nil {
+		return
+	}
+
+	if p.Selected == 0 {
+		err = &APINoResultsError{}
+	}
+
+	return
+}
+
+// paginate engraves the pagination options into the query of the request.
+func paginate(request PaginatedAPIRequest) {
+	p := request.GetPaginationOptions()
+	base := request.GetRequestDetails()
+
+	// Ensure the query parameters are available
+	if base.Query == nil {
+		base.Query = &url.Values{}
+	}
+
+	base.Query.Set("offset", strconv.FormatInt(p.Offset, 10))
+	base.Query.Set("limit", strconv.FormatInt(p.Limit, 10))
+
+	if p.Sort != "" {
+		base.Query.Set("sort", p.Sort)
+	}
+
+	if p.Order != "" {
+		base.Query.Set("order", string(p.Order))
+	}
+}
+
+// decodeList decodes a list response, passing every element of "list" to item
+// and all other fields into the pagination result.
+func decodeList(r io.Reader, p *PaginatedResult, item func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	fields := map[string]json.RawMessage{}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := t.(string)
+
+		if key != "list" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+
+			fields[key] = raw
+			continue
+		}
+
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		// An empty list may be null
+		if t == nil {
+			continue
+		}
+
+		if d, ok := t.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("unexpected %v in list", t)
+		}
+
+		for dec.More() {
+			if err := item(dec); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, p)
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %s, got %v", delim, t)
+	}
+
+	return nil
+}
+
+// limitedBody fails with *APIResponseTooLargeError once more than limit bytes are read.
+type limitedBody struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+// limitBody applies the API.MaxResponseSize to the given response body.
+func (api *API) limitBody(r io.Reader) io.Reader {
+	if api.MaxResponseSize <= 0 {
+		return r
+	}
+
+	return &limitedBody{r: r, remaining: api.MaxResponseSize, limit: api.MaxResponseSize}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Only fail if there actually is more data
+		var probe [1]byte
+
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, &APIResponseTooLargeError{Limit: l.limit}
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,119 @@
+package anydesk
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFixtureServer(t *testing.T, fixture string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadFile(fixture)
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+}
+
+func TestSessionListRequest_Each(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/session_list.json")
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	var ids []string
+
+	req := NewSessionListRequest(nil)
+	p, err := req.Each(api, func(node *SessionNode) error {
+		ids = append(ids, node.SessionID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SESSION1", "SESSION2"}, ids)
+	assert.Equal(t, int64(42), p.Count)
+	assert.Equal(t, int64(2), p.Selected)
+	assert.Equal(t, int64(10), p.Offset)
+	assert.Equal(t, int64(2), p.Limit)
+	assert.Equal(t, http.StatusOK, req.Meta().StatusCode)
+}
+
+func TestClientListRequest_Each(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/client_list_all.json")
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	stop := errors.New("stop")
+	calls := 0
+
+	p, err := NewClientListRequest(nil).Each(api, func(node *ClientNode) error {
+		calls++
+		assert.NotZero(t, node.ClientID)
+		assert.False(t, node.fetchedAt.IsZero())
+
+		if calls == 3 {
+			return stop
+		}
+
+		return nil
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, int64(0), p.Count)
+}
+
+func TestAPI_MaxResponseSize(t *testing.T) {
+	server := newFixtureServer(t, "./_tests/session_list.json")
+	defer server.Close()
+
+	data, err := ioutil.ReadFile("./_tests/session_list.json")
+	assert.NoError(t, err)
+
+	api := NewAPITestClient(t, server, "", "")
+	api.MaxResponseSize = int64(len(data))
+
+	_, err = NewSessionListRequest(nil).Do(api)
+	assert.NoError(t, err)
+
+	api.MaxResponseSize = int64(len(data)) - 1
+
+	_, err = NewSessionListRequest(nil).Do(api)
+	assert.Equal(t, &APIResponseTooLargeError{Limit: int64(len(data)) - 1}, err)
+
+	// the decoder stops reading at the end of the object, so the limit has to cut into it
+	api.MaxResponseSize = int64(len(data)) / 2
+
+	_, err = NewSessionListRequest(nil).Each(api, func(node *SessionNode) error {
+		return nil
+	})
+	assert.IsType(t, &APIResponseTooLargeError{}, err)
+}
+
+func TestDecodeList(t *testing.T) {
+	p := &PaginatedResult{}
+
+	err := decodeList(strings.NewReader(`{"count": 5, "list": null, "selected": 0}`), p, func(dec *json.Decoder) error {
+		t.Fatal("unexpected item")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), p.Count)
+
+	err = decodeList(strings.NewReader(`{"list": {}}`), p, func(dec *json.Decoder) error {
+		return nil
+	})
+	assert.Error(t, err)
+
+	err = decodeList(strings.NewReader(`{"list": [1, 2`), p, func(dec *json.Decoder) error {
+		var v int
+		return dec.Decode(&v)
+	})
+	assert.Error(t, err)
+}
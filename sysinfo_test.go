@@ -38,6 +38,11 @@ func TestNewSysinfoRequest(t *testing.T) {
 	a.Equal(599, resp.Sessions.Total)
 	a.True(resp.Standalone)
 
+	a.Equal(0.5, resp.Clients.OnlineRatio())
+	a.Equal(0.5, resp.SessionUtilization())
+	a.Equal(float64(0), resp.ClientUtilization())
+	a.Equal(map[string]float64{"demo1": 20.0 / 21, "demo2": 1.0 / 21}, resp.License.NamespaceUsage())
+
 	a.Len(resp.License.Namespaces, 2)
 	n1 := resp.License.Namespaces[0]
 	a.Equal("demo1", n1.Name)
@@ -0,0 +1,264 @@
+package anydesk
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TLSOptions contains the transport settings for enterprise on-premise endpoints.
+// Assign them to API.TLS, the transport is composed with the first request:
+//
+//   api := anydesk.NewAPI("license", "password")
+//   api.APIEndpoint = "https://yourinstance:8081"
+//   api.TLS = &anydesk.TLSOptions{
+//       CAFile: "/etc/ssl/internal-ca.pem",
+//       Pins:   []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+//   }
+type TLSOptions struct {
+	// Path of a PEM file with CA certificates to trust, in addition to the system pool.
+	CAFile string
+
+	// PEM encoded CA certificates to trust, in addition to the system pool.
+	CAPEM []byte
+
+	// SPKI pins, base64 encoded SHA-256 hashes of the subject public key info,
+	// optionally prefixed with "sha256/". If set, at least one certificate
+	// of the verified server chain has to match one of the pins.
+	Pins []string
+
+	// Paths of the PEM encoded client certificate and key, i.e. for mTLS through a reverse proxy.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// PEM encoded client certificate and key, alternative to the files.
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+
+	// Explicit proxy URL, i.e. "http://proxy:3128". Without, the environment proxy settings apply.
+	ProxyURL string
+
+	// Disables the certificate verification, pins are still checked against the leaf certificate. Only use for testing.
+	// If unset, the setting of the base transport applies.
+	InsecureSkipVerify bool
+}
+
+// TLSConfigError will be returned by the first request of an API with invalid TLS options.
+type TLSConfigError struct {
+	// Name of the misconfigured TLSOptions field
+	Field string
+
+	Err error
+}
+
+func (e *TLSConfigError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("invalid TLS option %s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TLSConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPinMismatch will be returned when no certificate of the server chain matches the configured pins.
+var ErrPinMismatch = errors.New("no certificate matches the configured pins")
+
+// Transport returns a http.Transport based on the given one, or the default transport, with the options applied.
+func (o *TLSOptions) Transport(base *http.Transport) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport := base.Clone()
+
+	config := transport.TLSClientConfig
+	if config == nil {
+		config = &tls.Config{}
+	}
+
+	// Keep the setting of the base transport unless the options disable the verification
+	if o.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	if o.CAFile != "" || len(o.CAPEM) > 0 {
+		pool, err := o.certPool()
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = pool
+	}
+
+	if o.ClientCertFile != "" || o.ClientKeyFile != "" || len(o.ClientCertPEM) > 0 || len(o.ClientKeyPEM) > 0 {
+		cert, err := o.clientCertificate()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(o.Pins) > 0 {
+		pins, err := parsePins(o.Pins)
+		if err != nil {
+			return nil, err
+		}
+
+		config.VerifyPeerCertificate = verifyPins(pins, config.InsecureSkipVerify)
+	}
+
+	if o.ProxyURL != "" {
+		proxy, err := url.Parse(o.ProxyURL)
+		if err == nil && (proxy.Scheme == "" || proxy.Host == "") {
+			err = errors.New("missing scheme or host")
+		}
+
+		if err != nil {
+			return nil, &TLSConfigError{Field: "ProxyURL", Err: err}
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	transport.TLSClientConfig = config
+
+	return transport, nil
+}
+
+func (o *TLSOptions) certPool() (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if o.CAFile != "" {
+		data, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, &TLSConfigError{Field: "CAFile", Err: err}
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, &TLSConfigError{Field: "CAFile", Err: errors.New("no certificates found")}
+		}
+	}
+
+	if len(o.CAPEM) > 0 && !pool.AppendCertsFromPEM(o.CAPEM) {
+		return nil, &TLSConfigError{Field: "CAPEM", Err: errors.New("no certificates found")}
+	}
+
+	return pool, nil
+}
+
+func (o *TLSOptions) clientCertificate() (tls.Certificate, error) {
+	certPEM, keyPEM := o.ClientCertPEM, o.ClientKeyPEM
+
+	if o.ClientCertFile != "" {
+		data, err := ioutil.ReadFile(o.ClientCertFile)
+		if err != nil {
+			return tls.Certificate{}, &TLSConfigError{Field: "ClientCertFile", Err: err}
+		}
+
+		certPEM = data
+	}
+
+	if o.ClientKeyFile != "" {
+		data, err := ioutil.ReadFile(o.ClientKeyFile)
+		if err != nil {
+			return tls.Certificate{}, &TLSConfigError{Field: "ClientKeyFile", Err: err}
+		}
+
+		keyPEM = data
+	}
+
+	if len(certPEM) == 0 {
+		return tls.Certificate{}, &TLSConfigError{Field: "ClientCertFile", Err: errors.New("missing client certificate")}
+	}
+
+	if len(keyPEM) == 0 {
+		return tls.Certificate{}, &TLSConfigError{Field: "ClientKeyFile", Err: errors.New("missing client key")}
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, &TLSConfigError{Field: "ClientCertFile", Err: err}
+	}
+
+	return cert, nil
+}
+
+// parsePins decodes the configured pins into raw SHA-256 hashes.
+func parsePins(pins []string) (map[string]bool, error) {
+	parsed := make(map[string]bool, len(pins))
+
+	for _, p := range pins {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(p, "sha256/"))
+		if err == nil && len(raw) != sha256.Size {
+			err = fmt.Errorf("expected %d bytes, got %d", sha256.Size, len(raw))
+		}
+
+		if err != nil {
+			return nil, &TLSConfigError{Field: "Pins", Err: fmt.Errorf("%q: %w", p, err)}
+		}
+
+		parsed[string(raw)] = true
+	}
+
+	return parsed, nil
+}
+
+// verifyPins returns a certificate verification that requires one certificate of the verified chain to match a pin.
+// Without verification, i.e. InsecureSkipVerify, only the leaf certificate is matched, as any further certificate
+// presented by the server is unverified and could be appended by anyone.
+func verifyPins(pins map[string]bool, insecure bool) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if !insecure {
+			for _, chain := range verifiedChains {
+				for _, cert := range chain {
+					if pins[spkiHash(cert)] {
+						return nil
+					}
+				}
+			}
+
+			return ErrPinMismatch
+		}
+
+		if len(rawCerts) == 0 {
+			return ErrPinMismatch
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+
+		if pins[spkiHash(leaf)] {
+			return nil
+		}
+
+		return ErrPinMismatch
+	}
+}
+
+// spkiHash returns the raw SHA-256 hash of the subject public key info of the certificate.
+func spkiHash(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return string(h[:])
+}
+
+// SPKIPin returns the pin of the certificate, as used by TLSOptions.Pins.
+func SPKIPin(cert *x509.Certificate) string {
+	return "sha256/" + base64.StdEncoding.EncodeToString([]byte(spkiHash(cert)))
+}
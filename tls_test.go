@@ -0,0 +1,280 @@
+package anydesk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTLSTestServer starts a TLS stub server answering "/auth" and returns a client API without trusted CA.
+func newTLSTestServer(t *testing.T) (*httptest.Server, *API) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadFile("./_tests/auth_response.json")
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+
+	return server, api
+}
+
+func serverCertPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// newTestCertificate generates a self signed certificate and key, both PEM encoded.
+func newTestCertificate(t *testing.T) (certPEM []byte, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "anydesk-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLSOptions_CA(t *testing.T) {
+	server, api := newTLSTestServer(t)
+	server.StartTLS()
+	defer server.Close()
+
+	api.APIEndpoint = server.URL
+
+	// the test certificate is not trusted by default
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+
+	api.TLS = &TLSOptions{CAPEM: serverCertPEM(server)}
+
+	resp, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST_LICENSE", resp.LicenseID)
+
+	// the http client itself is not modified
+	assert.Nil(t, api.HTTPClient.Transport)
+
+	dir, err := ioutil.TempDir("", "anydesk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "ca.pem")
+	assert.NoError(t, ioutil.WriteFile(file, serverCertPEM(server), 0600))
+
+	api.TLS = &TLSOptions{CAFile: file}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+}
+
+func TestTLSOptions_Pins(t *testing.T) {
+	server, api := newTLSTestServer(t)
+	server.StartTLS()
+	defer server.Close()
+
+	api.APIEndpoint = server.URL
+	api.TLS = &TLSOptions{
+		CAPEM: serverCertPEM(server),
+		Pins:  []string{SPKIPin(server.Certificate())},
+	}
+
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+
+	api.TLS = &TLSOptions{
+		CAPEM: serverCertPEM(server),
+		Pins:  []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+	}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, ErrPinMismatch), "expected pin mismatch, got %v", err)
+
+	// pins are checked even if the verification is skipped
+	api.TLS = &TLSOptions{
+		InsecureSkipVerify: true,
+		Pins:               []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+	}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, ErrPinMismatch), "expected pin mismatch, got %v", err)
+}
+
+func TestTLSOptions_PinsUnverifiedCertificates(t *testing.T) {
+	certPEM, _ := newTestCertificate(t)
+	block, _ := pem.Decode(certPEM)
+
+	extra, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	server, api := newTLSTestServer(t)
+	server.StartTLS()
+	defer server.Close()
+
+	// the server presents a leaf that is not pinned, followed by a pinned certificate
+	server.TLS.Certificates[0].Certificate = append(server.TLS.Certificates[0].Certificate, extra.Raw)
+
+	api.APIEndpoint = server.URL
+	api.TLS = &TLSOptions{
+		InsecureSkipVerify: true,
+		Pins:               []string{SPKIPin(extra)},
+	}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, ErrPinMismatch), "expected pin mismatch, got %v", err)
+
+	api.TLS = &TLSOptions{
+		InsecureSkipVerify: true,
+		Pins:               []string{SPKIPin(server.Certificate())},
+	}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+
+	// with verification, only the verified chain is matched
+	pins, err := parsePins([]string{SPKIPin(extra)})
+	assert.NoError(t, err)
+
+	leaf := server.Certificate()
+	verify := verifyPins(pins, false)
+
+	err = verify([][]byte{leaf.Raw, extra.Raw}, [][]*x509.Certificate{{leaf}})
+	assert.Equal(t, ErrPinMismatch, err)
+
+	assert.NoError(t, verify([][]byte{extra.Raw}, [][]*x509.Certificate{{extra}}))
+}
+
+func TestTLSOptions_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM := newTestCertificate(t)
+
+	block, _ := pem.Decode(certPEM)
+	clientCert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+
+	server, api := newTLSTestServer(t)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	api.APIEndpoint = server.URL
+	api.TLS = &TLSOptions{CAPEM: serverCertPEM(server)}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.Error(t, err)
+
+	api.TLS = &TLSOptions{CAPEM: serverCertPEM(server), ClientCertPEM: certPEM, ClientKeyPEM: keyPEM}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+}
+
+func TestTLSOptions_BaseTransport(t *testing.T) {
+	server, api := newTLSTestServer(t)
+	server.StartTLS()
+	defer server.Close()
+
+	api.APIEndpoint = server.URL
+	api.HTTPClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	api.TLS = &TLSOptions{Pins: []string{SPKIPin(server.Certificate())}}
+
+	// the verification stays disabled by the base transport, pins are checked against the leaf
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+
+	api.TLS = &TLSOptions{Pins: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}}
+
+	_, err = NewAuthenticationRequest().Do(api)
+	assert.True(t, errors.Is(err, ErrPinMismatch))
+}
+
+func TestTLSOptions_ProxyURL(t *testing.T) {
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = req.URL.String()
+
+		data, err := ioutil.ReadFile("./_tests/auth_response.json")
+		assert.NoError(t, err)
+
+		_, _ = rw.Write(data)
+	}))
+	defer proxy.Close()
+
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.APIEndpoint = "http://anydesk.invalid"
+	api.TLS = &TLSOptions{ProxyURL: proxy.URL}
+
+	_, err := NewAuthenticationRequest().Do(api)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://anydesk.invalid/auth", proxied)
+}
+
+func TestTLSOptions_ConfigErrors(t *testing.T) {
+	certPEM, _ := newTestCertificate(t)
+
+	tests := []struct {
+		options *TLSOptions
+		field   string
+	}{
+		{&TLSOptions{CAFile: "./_tests/missing.pem"}, "CAFile"},
+		{&TLSOptions{CAFile: "./_tests/auth_response.json"}, "CAFile"},
+		{&TLSOptions{CAPEM: []byte("nope")}, "CAPEM"},
+		{&TLSOptions{Pins: []string{"not base64"}}, "Pins"},
+		{&TLSOptions{Pins: []string{"sha256/AAAA"}}, "Pins"},
+		{&TLSOptions{ClientCertPEM: certPEM}, "ClientKeyFile"},
+		{&TLSOptions{ClientKeyFile: "./_tests/missing.pem"}, "ClientKeyFile"},
+		{&TLSOptions{ClientCertPEM: certPEM, ClientKeyPEM: []byte("nope")}, "ClientCertFile"},
+		{&TLSOptions{ProxyURL: "proxy:3128"}, "ProxyURL"},
+	}
+
+	for _, test := range tests {
+		api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+		api.TLS = test.options
+
+		_, err := NewAuthenticationRequest().Do(api)
+
+		var configErr *TLSConfigError
+		if assert.True(t, errors.As(err, &configErr), "expected config error for %s, got %v", test.field, err) {
+			assert.Equal(t, test.field, configErr.Field)
+		}
+	}
+
+	// TLS options can only be applied on top of a http.Transport
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.HTTPClient.Transport = http.NewFileTransport(http.Dir("."))
+	api.TLS = &TLSOptions{}
+
+	_, err := NewAuthenticationRequest().Do(api)
+
+	var configErr *TLSConfigError
+	assert.True(t, errors.As(err, &configErr))
+}
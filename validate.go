@@ -0,0 +1,74 @@
+package anydesk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by requests that check their parameters before they are sent.
+// API.Do calls Validate on every request that implements it.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError will be returned for requests with invalid parameters, before anything is sent.
+type ValidationError struct {
+	// Name of the invalid field, i.e. "Direction" or "Offset".
+	Field string
+
+	// Reason why the field is invalid.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the reason why the field is invalid.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func invalid(field string, format string, args ...interface{}) error {
+	return &ValidationError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// Validate checks the method and resource of the request.
+func (r *BaseRequest) Validate() error {
+	if r.Method == "" {
+		return invalid("Method", "must not be empty")
+	}
+
+	if len(r.Resource) == 0 || r.Resource[0] != '/' {
+		return invalid("Resource", "%q must start with a slash", r.Resource)
+	}
+
+	return nil
+}
+
+// validate checks the request, if it implements Validator.
+func validate(request APIRequest) error {
+	if v, ok := request.(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// resourceID returns the path segment of the resource following the given prefix, i.e. the
+// session ID of "/sessions/{id}". Requests built as struct literals are covered as well.
+func resourceID(resource string, prefix string) string {
+	if i := strings.IndexAny(resource, "?#"); i >= 0 {
+		resource = resource[:i]
+	}
+
+	if !strings.HasPrefix(resource, prefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(resource, prefix)
+}
@@ -0,0 +1,106 @@
+package anydesk
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionListRequest_Validate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		search *SessionListSearch
+		field  string
+	}{
+		{nil, ""},
+		{&SessionListSearch{Direction: DirectionOut, TimeFrom: now, TimeTo: now}, ""},
+		{&SessionListSearch{Direction: "sideways"}, "Direction"},
+		{&SessionListSearch{TimeFrom: now, TimeTo: now.Add(-time.Second)}, "TimeFrom"},
+		{&SessionListSearch{ClientID: -1}, "ClientID"},
+	}
+
+	for _, tt := range tests {
+		err := NewSessionListRequest(tt.search).Validate()
+
+		if tt.field == "" {
+			assert.NoError(t, err)
+			continue
+		}
+
+		var ve *ValidationError
+		if assert.True(t, errors.As(err, &ve), "%v", tt.search) {
+			assert.Equal(t, tt.field, ve.Field)
+		}
+	}
+}
+
+func TestPaginationOptions_Validate(t *testing.T) {
+	tests := []struct {
+		options *PaginationOptions
+		field   string
+	}{
+		{NewPaginationOptions(), ""},
+		{&PaginationOptions{Limit: 10, Sort: ClientSortAlias, Order: OrderAsc}, ""},
+		{&PaginationOptions{Offset: -1}, "Offset"},
+		{&PaginationOptions{Limit: -2}, "Limit"},
+		{&PaginationOptions{Order: "random"}, "Order"},
+		{&PaginationOptions{Sort: SessionSortStartTime}, "Sort"},
+	}
+
+	for _, tt := range tests {
+		req := NewClientListRequest(nil)
+		req.PaginationOptions = tt.options
+
+		err := req.Validate()
+
+		if tt.field == "" {
+			assert.NoError(t, err)
+			continue
+		}
+
+		assert.Equal(t, tt.field, err.(*ValidationError).Field)
+	}
+}
+
+func TestRequests_Validate(t *testing.T) {
+	assert.NoError(t, NewAuthenticationRequest().Validate())
+	assert.NoError(t, NewClientDetailRequest(1).Validate())
+	assert.Equal(t, "ClientID", NewClientDetailRequest(0).Validate().(*ValidationError).Field)
+	assert.Equal(t, "SessionID", NewSessionCommentChangeRequest("", "").Validate().(*ValidationError).Field)
+	assert.Equal(t, "Method", (&BaseRequest{Resource: "/auth"}).Validate().(*ValidationError).Field)
+	assert.Equal(t, "Resource", (&BaseRequest{Method: "GET"}).Validate().(*ValidationError).Field)
+}
+
+func TestRequests_ValidateLiterals(t *testing.T) {
+	assert.NoError(t, (&ClientDetailRequest{&BaseRequest{Method: "GET", Resource: "/clients/123"}}).Validate())
+	assert.NoError(t, (&SessionCommentChangeRequest{BaseRequest: &BaseRequest{Method: "PATCH", Resource: "/sessions/S1"}}).Validate())
+
+	err := (&ClientDetailRequest{&BaseRequest{Method: "GET", Resource: "/clients/abc"}}).Validate()
+	assert.Equal(t, "ClientID", err.(*ValidationError).Field)
+
+	err = (&SessionCommentChangeRequest{BaseRequest: &BaseRequest{Method: "PATCH", Resource: "/sessions/?x=1"}}).Validate()
+	assert.Equal(t, "SessionID", err.(*ValidationError).Field)
+
+	err = (&ClientDetailRequest{}).Validate()
+	assert.Equal(t, "Resource", err.(*ValidationError).Field)
+}
+
+func TestAPI_Do_Validate(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	api := NewAPITestClient(t, server, "", "")
+
+	_, err := NewSessionListRequest(&SessionListSearch{Direction: "sideways"}).Do(api)
+	assert.EqualError(t, err, `invalid Direction: unknown session direction "sideways"`)
+	assert.Equal(t, int32(0), calls)
+}
@@ -0,0 +1,281 @@
+package anydesk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRequestAge is the maximum accepted difference between the request timestamp
+// and the current time used by VerifyRequest.
+const DefaultMaxRequestAge = 5 * time.Minute
+
+// DefaultMaxRequestBodySize is the maximum request body size read by RequestVerifier.Verify.
+const DefaultMaxRequestBodySize = 1 << 20
+
+// SecretLookup returns the API password for the given license ID.
+// An error indicates that the license is unknown.
+type SecretLookup func(licenseID string) (apiPassword string, err error)
+
+// VerificationReason describes why a request signature could not be verified.
+type VerificationReason int
+
+const (
+	// VerificationMissingHeader indicates that the request had no "Authorization" header.
+	VerificationMissingHeader VerificationReason = iota + 1
+
+	// VerificationMalformedHeader indicates that the "Authorization" header could not be parsed.
+	VerificationMalformedHeader
+
+	// VerificationUnknownLicense indicates that the secret lookup failed for the license ID.
+	VerificationUnknownLicense
+
+	// VerificationExpiredTimestamp indicates that the request timestamp is too far off the current time.
+	VerificationExpiredTimestamp
+
+	// VerificationInvalidToken indicates that the request token does not match the request.
+	VerificationInvalidToken
+
+	// VerificationUnreadableBody indicates that the request body could not be read.
+	VerificationUnreadableBody
+
+	// VerificationBodyTooLarge indicates that the request body exceeds the maximum body size.
+	VerificationBodyTooLarge
+)
+
+// String returns a human readable description of the reason.
+func (r VerificationReason) String() string {
+	switch r {
+	case VerificationMissingHeader:
+		return "missing authorization header"
+	case VerificationMalformedHeader:
+		return "malformed authorization header"
+	case VerificationUnknownLicense:
+		return "unknown license"
+	case VerificationExpiredTimestamp:
+		return "request timestamp out of range"
+	case VerificationInvalidToken:
+		return "invalid request token"
+	case VerificationUnreadableBody:
+		return "unreadable request body"
+	case VerificationBodyTooLarge:
+		return "request body too large"
+	default:
+		return "unknown reason"
+	}
+}
+
+// Code returns the error code the AnyDesk API uses for the reason, i.e. "invalid_token".
+func (r VerificationReason) Code() string {
+	switch r {
+	case VerificationMissingHeader, VerificationMalformedHeader:
+		return "invalid_auth"
+	case VerificationUnknownLicense:
+		return "invalid_license"
+	default:
+		return "invalid_token"
+	}
+}
+
+// RequestVerificationError will be returned when a request signature could not be verified.
+type RequestVerificationError struct {
+	// Reason of the failure.
+	Reason VerificationReason
+
+	// License ID as given by the request, if it could be parsed.
+	LicenseID string
+
+	// Underlying error, i.e. as returned by the SecretLookup.
+	Err error
+}
+
+func (e *RequestVerificationError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Reason, e.Err)
+	}
+
+	return e.Reason.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *RequestVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// RequestVerifier verifies the signature of requests made with the AnyDesk request signing scheme.
+type RequestVerifier struct {
+	// Lookup of the API password by license ID.
+	Lookup SecretLookup
+
+	// Maximum accepted difference between request timestamp and current time.
+	// Defaults to DefaultMaxRequestAge if zero.
+	MaxAge time.Duration
+
+	// Maximum request body size in bytes that is read to verify the token.
+	// Defaults to DefaultMaxRequestBodySize if zero.
+	MaxBodySize int64
+
+	// Returns the current time, defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewRequestVerifier returns a verifier using the given secret lookup, DefaultMaxRequestAge
+// and DefaultMaxRequestBodySize.
+func NewRequestVerifier(lookup SecretLookup) *RequestVerifier {
+	return &RequestVerifier{
+		Lookup:      lookup,
+		MaxAge:      DefaultMaxRequestAge,
+		MaxBodySize: DefaultMaxRequestBodySize,
+		Now:         time.Now,
+	}
+}
+
+// VerifyRequest verifies the "Authorization" header of the given request with DefaultMaxRequestAge.
+// Returns the verified license ID or a *RequestVerificationError.
+func VerifyRequest(req *http.Request, lookup SecretLookup) (licenseID string, err error) {
+	return NewRequestVerifier(lookup).Verify(req)
+}
+
+// Verify parses the "AD license:timestamp:token" header of the given request, recomputes the
+// request string like BaseRequest.GetRequestString and compares the resulting token.
+// The request body is read and replaced, so it can still be read by the caller.
+// Returns the verified license ID or a *RequestVerificationError.
+func (v *RequestVerifier) Verify(req *http.Request) (licenseID string, err error) {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return "", &RequestVerificationError{Reason: VerificationMissingHeader}
+	}
+
+	if !strings.HasPrefix(header, "AD ") {
+		return "", &RequestVerificationError{Reason: VerificationMalformedHeader}
+	}
+
+	parts := strings.Split(strings.TrimPrefix(header, "AD "), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return "", &RequestVerificationError{Reason: VerificationMalformedHeader}
+	}
+
+	licenseID = parts[0]
+
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", &RequestVerificationError{Reason: VerificationMalformedHeader, LicenseID: licenseID, Err: err}
+	}
+
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+
+	age := now().Sub(time.Unix(ts, 0))
+	if age < 0 {
+		age = -age
+	}
+
+	maxAge := v.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxRequestAge
+	}
+
+	if age > maxAge {
+		return "", &RequestVerificationError{Reason: VerificationExpiredTimestamp, LicenseID: licenseID}
+	}
+
+	password, err := v.Lookup(licenseID)
+	if err != nil {
+		return "", &RequestVerificationError{Reason: VerificationUnknownLicense, LicenseID: licenseID, Err: err}
+	}
+
+	var content []byte
+	if req.Body != nil {
+		limit := v.MaxBodySize
+		if limit == 0 {
+			limit = DefaultMaxRequestBodySize
+		}
+
+		// Read one byte more than allowed, to detect oversized bodies
+		content, err = ioutil.ReadAll(io.LimitReader(req.Body, limit+1))
+		if err != nil {
+			return "", &RequestVerificationError{Reason: VerificationUnreadableBody, LicenseID: licenseID, Err: err}
+		}
+
+		if int64(len(content)) > limit {
+			return "", &RequestVerificationError{Reason: VerificationBodyTooLarge, LicenseID: licenseID}
+		}
+
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(content))
+	}
+
+	base := &BaseRequest{
+		Method:    req.Method,
+		Resource:  req.URL.RequestURI(),
+		Timestamp: ts,
+		Content:   content,
+	}
+
+	signer := &API{LicenseID: licenseID, APIPassword: password}
+	if !hmac.Equal([]byte(parts[2]), []byte(signer.GetRequestToken(base))) {
+		return "", &RequestVerificationError{Reason: VerificationInvalidToken, LicenseID: licenseID}
+	}
+
+	return licenseID, nil
+}
+
+// Handler returns a middleware that only passes verified requests to the next handler.
+// Rejected requests receive a http.StatusUnauthorized with an AnyDesk like error response.
+// The verified license ID can be read with LicenseIDFromContext.
+func (v *RequestVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		licenseID, err := v.Verify(req)
+		if err != nil {
+			WriteVerificationError(rw, req, err)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), licenseIDContextKey, licenseID)
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// WriteVerificationError responds with the AnyDesk error response for the given verification error.
+func WriteVerificationError(rw http.ResponseWriter, req *http.Request, err error) {
+	code := "invalid_auth"
+	if ve, ok := err.(*RequestVerificationError); ok {
+		code = ve.Reason.Code()
+	}
+
+	data, _ := json.Marshal(&AuthenticationResponse{
+		Result:   "error",
+		Error:    err.Error(),
+		Code:     code,
+		Method:   req.Method,
+		Resource: req.URL.RequestURI(),
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusUnauthorized)
+	_, _ = rw.Write(data)
+}
+
+type contextKey int
+
+const licenseIDContextKey contextKey = iota
+
+// LicenseIDFromContext returns the license ID verified by RequestVerifier.Handler.
+func LicenseIDFromContext(ctx context.Context) (licenseID string, ok bool) {
+	licenseID, ok = ctx.Value(licenseIDContextKey).(string)
+	return
+}
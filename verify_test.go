@@ -0,0 +1,174 @@
+package anydesk
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSecretLookup(licenseID string) (string, error) {
+	if licenseID != "1438129266231705" {
+		return "", errors.New("unknown")
+	}
+
+	return "UYETICGU2CT3KES", nil
+}
+
+// newSignedRequest returns a request signed like API.Do would sign it.
+func newSignedRequest(t *testing.T, method string, resource string, content string, ts time.Time) *http.Request {
+	api := NewAPI("1438129266231705", "UYETICGU2CT3KES")
+	api.APIEndpoint = "http://127.0.0.1"
+
+	r := &BaseRequest{
+		Method:    method,
+		Resource:  resource,
+		Timestamp: ts.Unix(),
+		Content:   []byte(content),
+	}
+
+	req, err := r.GetHTTPRequest(api)
+	assert.NoError(t, err)
+
+	return req
+}
+
+func TestVerifyRequest(t *testing.T) {
+	req := newSignedRequest(t, "PATCH", "/sessions/123?x=1", `{"comment":"test"}`, time.Now())
+
+	licenseID, err := VerifyRequest(req, testSecretLookup)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal("1438129266231705", licenseID)
+
+	// Body must still be readable
+	body, err := ioutil.ReadAll(req.Body)
+	a.NoError(err)
+	a.Equal(`{"comment":"test"}`, string(body))
+}
+
+func TestVerifyRequest_Failures(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(req *http.Request)
+		reason VerificationReason
+	}{
+		{"missing header", func(req *http.Request) { req.Header.Del("Authorization") }, VerificationMissingHeader},
+		{"wrong scheme", func(req *http.Request) { req.Header.Set("Authorization", "Basic abc") }, VerificationMalformedHeader},
+		{"missing parts", func(req *http.Request) { req.Header.Set("Authorization", "AD 1438129266231705:1") }, VerificationMalformedHeader},
+		{"bad timestamp", func(req *http.Request) { req.Header.Set("Authorization", "AD 1438129266231705:x:abc") }, VerificationMalformedHeader},
+		{"unknown license", func(req *http.Request) {
+			req.Header.Set("Authorization", fmt.Sprintf("AD 1:%d:abc", time.Now().Unix()))
+		}, VerificationUnknownLicense},
+		{"tampered resource", func(req *http.Request) { req.URL.Path = "/sessions/124" }, VerificationInvalidToken},
+		{"tampered method", func(req *http.Request) { req.Method = "GET" }, VerificationInvalidToken},
+		{"unreadable body", func(req *http.Request) {
+			req.Body = ioutil.NopCloser(iotest.TimeoutReader(strings.NewReader("{}")))
+		}, VerificationUnreadableBody},
+	}
+
+	for _, c := range cases {
+		req := newSignedRequest(t, "PATCH", "/sessions/123", `{}`, time.Now())
+		c.modify(req)
+
+		_, err := VerifyRequest(req, testSecretLookup)
+
+		if assert.IsType(t, &RequestVerificationError{}, err, c.name) {
+			assert.Equal(t, c.reason, err.(*RequestVerificationError).Reason, c.name)
+		}
+	}
+}
+
+func TestRequestVerifier_MaxAge(t *testing.T) {
+	v := NewRequestVerifier(testSecretLookup)
+	v.MaxAge = time.Minute
+	v.Now = func() time.Time {
+		return time.Unix(1445440997, 0)
+	}
+
+	_, err := v.Verify(newSignedRequest(t, "GET", "/auth", "", time.Unix(1445440997-59, 0)))
+	assert.NoError(t, err)
+
+	_, err = v.Verify(newSignedRequest(t, "GET", "/auth", "", time.Unix(1445440997+61, 0)))
+	assert.IsType(t, &RequestVerificationError{}, err)
+	assert.Equal(t, VerificationExpiredTimestamp, err.(*RequestVerificationError).Reason)
+}
+
+func TestRequestVerifier_MaxBodySize(t *testing.T) {
+	v := NewRequestVerifier(testSecretLookup)
+	v.MaxBodySize = 8
+
+	_, err := v.Verify(newSignedRequest(t, "PATCH", "/sessions/123", `{"a":1}`, time.Now()))
+	assert.NoError(t, err)
+
+	_, err = v.Verify(newSignedRequest(t, "PATCH", "/sessions/123", `{"comment":"too long"}`, time.Now()))
+	if assert.IsType(t, &RequestVerificationError{}, err) {
+		assert.Equal(t, VerificationBodyTooLarge, err.(*RequestVerificationError).Reason)
+	}
+}
+
+func TestRequestVerifier_ZeroValue(t *testing.T) {
+	v := &RequestVerifier{Lookup: testSecretLookup}
+
+	_, err := v.Verify(newSignedRequest(t, "PATCH", "/sessions/123", `{}`, time.Now().Add(-time.Minute)))
+	assert.NoError(t, err)
+
+	_, err = v.Verify(newSignedRequest(t, "PATCH", "/sessions/123", `{}`, time.Now().Add(-DefaultMaxRequestAge-time.Minute)))
+	if assert.IsType(t, &RequestVerificationError{}, err) {
+		assert.Equal(t, VerificationExpiredTimestamp, err.(*RequestVerificationError).Reason)
+	}
+}
+
+func TestRequestVerifier_Handler(t *testing.T) {
+	h := NewRequestVerifier(testSecretLookup).Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		licenseID, ok := LicenseIDFromContext(req.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "1438129266231705", licenseID)
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedRequest(t, "GET", "/auth", "", time.Now()))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedRequest(t, "GET", "/auth", "", time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{
+		"result": "error",
+		"error": "request timestamp out of range",
+		"code": "invalid_token",
+		"method": "GET",
+		"resource": "/auth",
+		"request-time": "",
+		"content-hash": "",
+		"license-id": ""
+	}`, rec.Body.String())
+}
+
+func ExampleRequestVerifier_Handler() {
+	verifier := NewRequestVerifier(func(licenseID string) (string, error) {
+		if licenseID != "license" {
+			return "", errors.New("unknown license")
+		}
+
+		return "password", nil
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sysinfo", func(rw http.ResponseWriter, req *http.Request) {
+		licenseID, _ := LicenseIDFromContext(req.Context())
+		fmt.Fprintf(rw, `{"license":{"license-id":%q}}`, licenseID)
+	})
+
+	_ = http.ListenAndServe("127.0.0.1:8081", verifier.Handler(mux))
+}